@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/estk/sourcerer/sourcerer"
+)
+
+// handleManifest checks every entry of the manifest at filename.
+func handleManifest(ctx context.Context, filename string) ([]sourcerer.Result, error) {
+	conf, err := parseConfig(filename)
+	if err != nil {
+		return nil, err
+	}
+	if len(conf.Sources) == 0 && !*allowEmptyFlag {
+		warnf("%s has no sources; it may be empty or truncated (see -allow-empty)", filename)
+	}
+	results, err := checker.CheckNewer(ctx, conf)
+	for i := range results {
+		results[i].Manifest = filename
+	}
+	return results, err
+}
+
+// manifestResult is the outcome of handling the manifest at index.
+type manifestResult struct {
+	index   int
+	results []sourcerer.Result
+	err     error
+}
+
+// checkManifests handles manifests on a pool of -concurrency workers, adding
+// every result to counts. Workers report to a single collector which calls
+// emit, if not nil, with each manifest's results as soon as all manifests
+// before it are done, so output can be streamed but ordered like manifests.
+func checkManifests(ctx context.Context, manifests []string, counts *tally, emit func([]sourcerer.Result)) ([][]sourcerer.Result, []error) {
+	checker.ResetMemo()
+	work := make(chan int)
+	done := make(chan manifestResult)
+	var wg sync.WaitGroup
+	for w := 0; w < *concurrencyFlag; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				rs, err := handleManifest(ctx, manifests[i])
+				counts.add(rs)
+				done <- manifestResult{index: i, results: rs, err: err}
+			}
+		}()
+	}
+	go func() {
+		for i := range manifests {
+			work <- i
+		}
+		close(work)
+		wg.Wait()
+		close(done)
+	}()
+
+	results := make([][]sourcerer.Result, len(manifests))
+	errs := make([]error, len(manifests))
+	finished := make([]bool, len(manifests))
+	next := 0
+	for mr := range done {
+		results[mr.index], errs[mr.index] = mr.results, mr.err
+		finished[mr.index] = true
+		for ; next < len(manifests) && finished[next]; next++ {
+			if emit != nil {
+				emit(results[next])
+			}
+		}
+	}
+	if hits := checker.MemoHits(); hits > 0 {
+		infof("reused %d upstream lookups of sources checked more than once", hits)
+	}
+	return results, errs
+}
+
+// printManifest prints the shown results of one manifest in a single write,
+// through -template if it is set.
+func printManifest(results []sourcerer.Result) {
+	if resultTemplate != nil {
+		printTemplate(results)
+		return
+	}
+	msgs := []string{}
+	for _, r := range results {
+		if shown(r) {
+			msgs = append(msgs, message(r))
+		}
+	}
+	if len(msgs) > 0 {
+		fmt.Printf("%s:\n%s\n\n", results[0].Manifest, strings.Join(msgs, "\n"))
+	}
+}
+
+// printFlat prints the results of every manifest as one table sorted by
+// source, with the manifest of each as a column, for -flat.
+func printFlat(results [][]sourcerer.Result) {
+	all := []sourcerer.Result{}
+	for _, rs := range results {
+		for _, r := range rs {
+			if shown(r) {
+				all = append(all, r)
+			}
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		if li, lj := resultLabel(all[i]), resultLabel(all[j]); li != lj {
+			return li < lj
+		}
+		return all[i].Manifest < all[j].Manifest
+	})
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SOURCE\tSTATUS\tCURRENT\tLATEST\tMANIFEST")
+	for _, r := range all {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", resultLabel(r), r.Status, r.CurrentTag, r.LatestTag, r.Manifest)
+	}
+	w.Flush()
+}
+
+// tally counts results by status. It is safe for concurrent use.
+type tally struct {
+	mu       sync.Mutex
+	total    int
+	byStatus map[sourcerer.Status]int
+}
+
+func (t *tally) add(results []sourcerer.Result) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.byStatus == nil {
+		t.byStatus = map[sourcerer.Status]int{}
+	}
+	for _, r := range results {
+		t.total++
+		t.byStatus[r.Status]++
+	}
+}
+
+// summary describes the counts for a run over the given number of manifests.
+// Pinned, missing, mismatched, advanced, not found and end-of-life sources
+// are only mentioned when there are any.
+func (t *tally) summary(manifests int) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := fmt.Sprintf("Checked %d sources across %d manifests: %d up to date, %d outdated, %d unknown, %d raw",
+		t.total, manifests, t.byStatus[sourcerer.StatusUpToDate], t.byStatus[sourcerer.StatusOutdated], t.byStatus[sourcerer.StatusUnknown], t.byStatus[sourcerer.StatusRaw])
+	for _, c := range []struct {
+		status sourcerer.Status
+		desc   string
+	}{{sourcerer.StatusPinned, "pinned"}, {sourcerer.StatusMissing, "missing"}, {sourcerer.StatusMismatch, "mismatched"}, {sourcerer.StatusAdvanced, "advanced"}, {sourcerer.StatusNotFound, "not found"}, {sourcerer.StatusEOL, "end-of-life"}} {
+		if n := t.byStatus[c.status]; n > 0 {
+			s += fmt.Sprintf(", %d %s", n, c.desc)
+		}
+	}
+	return s
+}
+
+// needsAttention reports whether a result with status s means the source
+// drifted from its manifest: it is outdated, missing, mismatched, not found,
+// end-of-life or its branch advanced.
+func needsAttention(s sourcerer.Status) bool {
+	switch s {
+	case sourcerer.StatusOutdated, sourcerer.StatusMissing, sourcerer.StatusMismatch, sourcerer.StatusAdvanced, sourcerer.StatusNotFound, sourcerer.StatusEOL:
+		return true
+	}
+	return false
+}
+
+// shown reports whether r is included in the output; with -quiet only
+// sources needing attention are.
+func shown(r sourcerer.Result) bool {
+	return !*quietFlag || needsAttention(r.Status)
+}
+
+// shouldFail reports whether a result with status s fails the run according
+// to the -fail-on flag.
+func shouldFail(s sourcerer.Status) bool {
+	switch *failOnFlag {
+	case "outdated":
+		return needsAttention(s)
+	case "unknown":
+		return needsAttention(s) || s == sourcerer.StatusUnknown
+	}
+	return false
+}
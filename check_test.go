@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEmptyManifestWarning(t *testing.T) {
+	var logs bytes.Buffer
+	logger.SetOutput(&logs)
+	t.Cleanup(func() {
+		logger.SetOutput(os.Stderr)
+		*allowEmptyFlag = false
+	})
+	if err := setup(); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		desc       string
+		data       string
+		allowEmpty bool
+		warn       bool
+	}{
+		{"blank", "", false, true},
+		{"blank with -allow-empty", "", true, false},
+		{"empty sources", "sources: []\n", false, true},
+		{"empty sources with -allow-empty", "sources: []\n", true, false},
+	} {
+		t.Run(c.desc, func(t *testing.T) {
+			logs.Reset()
+			*allowEmptyFlag = c.allowEmpty
+			manifest := filepath.Join(t.TempDir(), manifestName)
+			if err := ioutil.WriteFile(manifest, []byte(c.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			results, err := handleManifest(context.Background(), manifest)
+			if err != nil || len(results) != 0 {
+				t.Fatalf("got %d results, %v; want none", len(results), err)
+			}
+			if warned := strings.Contains(logs.String(), "has no sources"); warned != c.warn {
+				t.Errorf("got warning %v, want %v: %q", warned, c.warn, logs.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/estk/sourcerer/sourcerer"
+)
+
+var allowDuplicatesFlag = flag.Bool("allow-duplicates", false, "do not warn about a repo pinned to different tags in different manifests")
+
+// pinKey identifies what a source pins across manifests: its provider and
+// repo, or name for registry and exec providers.
+func pinKey(e sourcerer.SourceEntry) string {
+	provider := e.Provider
+	if provider == "" {
+		provider = sourcerer.ProviderGitHub
+	}
+	subject := strings.TrimSuffix(strings.TrimPrefix(strings.ToLower(e.Repo), "https://"), ".git")
+	if subject == "" {
+		subject = e.Name
+	}
+	return provider + " " + subject
+}
+
+// warnConflicts warns about every repo pinned to different tags, or branch
+// commits, by different manifests. A single manifest pinning a repo at
+// several tags, such as for two of its assets, is not a conflict. Manifests
+// that fail to parse are left to be reported by the check itself.
+func warnConflicts(manifests []string) {
+	if *allowDuplicatesFlag {
+		return
+	}
+	// pins maps each pinKey to the manifests pinning each tag.
+	pins := map[string]map[string][]string{}
+	for _, m := range manifests {
+		conf, err := parseConfig(m)
+		if err != nil {
+			continue
+		}
+		for _, e := range conf.Sources {
+			if e.URL != "" || e.LatestAsset != "" || !selected(e) {
+				continue
+			}
+			tag := e.Tag
+			if len(e.Tags) > 0 {
+				tag = strings.Join(e.Tags, ", ")
+			} else if e.Branch != "" {
+				tag = e.Branch + "@" + e.Commit
+			}
+			key := pinKey(e)
+			if pins[key] == nil {
+				pins[key] = map[string][]string{}
+			}
+			pins[key][tag] = append(pins[key][tag], m)
+		}
+	}
+	keys := []string{}
+	for key, tags := range pins {
+		if len(tags) > 1 && len(pinningManifests(tags)) > 1 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		var found []string
+		for tag, ms := range pins[key] {
+			found = append(found, fmt.Sprintf("%s in %s", tag, strings.Join(dedupe(ms), ", ")))
+		}
+		sort.Strings(found)
+		warnf("%s is pinned to different versions: %s", key[strings.Index(key, " ")+1:], strings.Join(found, "; "))
+	}
+}
+
+// pinningManifests returns the distinct manifests pinning any of tags. With
+// several tags and several manifests, some two manifests differ in the tag
+// they pin.
+func pinningManifests(tags map[string][]string) []string {
+	all := []string{}
+	for _, ms := range tags {
+		all = append(all, ms...)
+	}
+	return dedupe(all)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWarnConflicts(t *testing.T) {
+	var logs bytes.Buffer
+	logger.SetOutput(&logs)
+	t.Cleanup(func() { logger.SetOutput(os.Stderr) })
+	if err := setup(); err != nil {
+		t.Fatal(err)
+	}
+	manifest := func(data string) string {
+		m := filepath.Join(t.TempDir(), manifestName)
+		if err := ioutil.WriteFile(m, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+	both := "sources:\n- repo: github.com/org/lib\n  tag: v1.0.0\n- repo: github.com/org/lib\n  tag: v2.0.0\n"
+	v1 := manifest("sources:\n- repo: github.com/org/lib\n  tag: v1.0.0\n")
+	v2 := manifest("sources:\n- repo: github.com/org/lib\n  tag: v2.0.0\n")
+	for _, c := range []struct {
+		desc      string
+		manifests []string
+		warn      bool
+	}{
+		{"one manifest at two tags", []string{manifest(both)}, false},
+		{"two manifests at one tag", []string{v1, manifest("sources:\n- repo: github.com/org/lib\n  tag: v1.0.0\n")}, false},
+		{"two manifests at two tags", []string{v1, v2}, true},
+		{"one manifest at two tags and another", []string{manifest(both), v1}, true},
+	} {
+		t.Run(c.desc, func(t *testing.T) {
+			logs.Reset()
+			warnConflicts(c.manifests)
+			if warned := strings.Contains(logs.String(), "pinned to different versions"); warned != c.warn {
+				t.Errorf("got warning %v, want %v: %q", warned, c.warn, logs.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/estk/sourcerer/sourcerer"
+	"gopkg.in/yaml.v2"
+)
+
+// searchRoots returns the cleaned, deduplicated roots to search, defaulting to
+// the current directory. It is an error for a root not to exist.
+func searchRoots(args []string) ([]string, error) {
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+	roots := []string{}
+	for _, r := range args {
+		r = filepath.Clean(r)
+		if _, err := os.Stat(r); err != nil {
+			return nil, fmt.Errorf("cannot search %s: %v", r, err)
+		}
+		roots = append(roots, r)
+	}
+	return dedupe(roots), nil
+}
+
+// stdinManifest names the manifest read from stdin with -file -.
+const stdinManifest = "<stdin>"
+
+// adhocManifest names the manifest of the single source given with -repo and
+// -tag.
+const adhocManifest = "<command line>"
+
+var (
+	stdinOnce sync.Once
+	stdinData []byte
+	stdinErr  error
+)
+
+// manifestFiles returns the manifests given with -file, in order, with - as
+// stdinManifest. It is an error for a file not to exist.
+func manifestFiles(files []string) (manifests []string, ok bool) {
+	ok = true
+	seen := map[string]bool{}
+	for _, f := range files {
+		if f == "-" {
+			f = stdinManifest
+		} else if fi, err := os.Stat(f); err != nil {
+			errorf("cannot check %s: %v", f, err)
+			ok = false
+			continue
+		} else if fi.IsDir() {
+			errorf("cannot check %s: is a directory", f)
+			ok = false
+			continue
+		}
+		if !seen[f] {
+			seen[f] = true
+			manifests = append(manifests, f)
+		}
+	}
+	infof("checking %d manifests given with -file", len(manifests))
+	return manifests, ok
+}
+
+// parseConfig reads and validates the manifest at filename with the settings
+// of checker.
+func parseConfig(filename string) (sourcerer.Config, error) {
+	data, err := readManifest(filename)
+	if err != nil {
+		return sourcerer.Config{}, err
+	}
+	return checker.ParseConfig(filename, data)
+}
+
+// readManifest returns the contents of the manifest at path. stdinManifest is
+// read from stdin once and kept, so that it can be checked again with -watch,
+// and adhocManifest is a YAML manifest of -repo at -tag.
+func readManifest(path string) ([]byte, error) {
+	if path == adhocManifest {
+		return yaml.Marshal(sourcerer.Config{Sources: []sourcerer.SourceEntry{{Repo: *repoFlag, Tag: *tagFlag}}})
+	}
+	if path != stdinManifest {
+		return ioutil.ReadFile(path)
+	}
+	stdinOnce.Do(func() {
+		stdinData, stdinErr = ioutil.ReadAll(os.Stdin)
+	})
+	return stdinData, stdinErr
+}
+
+// dedupe sorts paths and removes duplicates.
+func dedupe(paths []string) []string {
+	sort.Strings(paths)
+	out := []string{}
+	for i, p := range paths {
+		if i == 0 || p != paths[i-1] {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// searchForManifests walks root and returns every file whose name matches one
+// of the glob patterns in names. Directories whose name or path matches one of
+// the excludes are not walked, nor are those more than maxDepth levels below
+// root unless maxDepth is negative. With followSymlinks, symlinks to
+// directories are walked too, each directory only once so that symlink cycles
+// end. Paths that cannot be read are logged and skipped; their errors are
+// combined into the returned error.
+func searchForManifests(root string, names, excludes []string, maxDepth int, followSymlinks bool) ([]string, error) {
+	manifests := []string{}
+	walkErrs := []string{}
+	// visited holds the directories walked with followSymlinks, compared by
+	// os.SameFile, i.e. device and inode.
+	var visited []os.FileInfo
+	var visit filepath.WalkFunc
+	visit = func(path string, f os.FileInfo, err error) error {
+		if err != nil {
+			debugf("skipping %s: %v", path, err)
+			walkErrs = append(walkErrs, err.Error())
+			return nil
+		}
+		if f.IsDir() {
+			if path != root && (matchesAny(excludes, f.Name()) || matchesAny(excludes, path)) {
+				return filepath.SkipDir
+			}
+			if maxDepth >= 0 && depth(root, path) > maxDepth {
+				return filepath.SkipDir
+			}
+			if followSymlinks {
+				for _, v := range visited {
+					if os.SameFile(v, f) {
+						debugf("skipping %s: already searched", path)
+						return filepath.SkipDir
+					}
+				}
+				visited = append(visited, f)
+			}
+			return nil
+		}
+		if f.Mode()&os.ModeSymlink != 0 && followSymlinks {
+			if target, err := os.Stat(path); err == nil && target.IsDir() {
+				// Walk does not follow symlinks, except to its root.
+				filepath.Walk(path+string(filepath.Separator), visit)
+				return nil
+			}
+		}
+		if matchesAny(names, filepath.Base(path)) {
+			manifests = append(manifests, path)
+		}
+		return nil
+	}
+	filepath.Walk(root, visit)
+	if len(walkErrs) > 0 {
+		return manifests, fmt.Errorf("unable to search all of %s:\n%s", root, strings.Join(walkErrs, "\n"))
+	}
+	return manifests, nil
+}
+
+// depth returns how many levels path is below root, root itself being 0.
+func depth(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
+
+// matchesAny reports whether name matches any of the glob patterns.
+func matchesAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := filepath.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// writeTree creates the files at paths, relative to a new temporary
+// directory, which it returns.
+func writeTree(t *testing.T, paths ...string) string {
+	root := t.TempDir()
+	for _, p := range paths {
+		p = filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte("sources: []\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// relative returns paths relative to root, with slashes.
+func relative(t *testing.T, root string, paths []string) []string {
+	rel := []string{}
+	for _, p := range paths {
+		r, err := filepath.Rel(root, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rel = append(rel, filepath.ToSlash(r))
+	}
+	return rel
+}
+
+func TestSearchForManifestsExcludes(t *testing.T) {
+	root := writeTree(t,
+		"SOURCES",
+		"app/SOURCES",
+		"node_modules/dep/SOURCES",
+		"app/node_modules/dep/SOURCES",
+		"vendor/SOURCES",
+		"third_party/vendor/SOURCES",
+	)
+	excludes := []string{"node_modules", filepath.Join(root, "vendor")}
+	found, err := searchForManifests(root, []string{manifestName}, excludes, -1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"SOURCES", "app/SOURCES", "third_party/vendor/SOURCES"}
+	if got := relative(t, root, found); !reflect.DeepEqual(got, want) {
+		t.Errorf("found %v, want %v", got, want)
+	}
+}
+
+func TestSearchForManifestsMaxDepth(t *testing.T) {
+	root := writeTree(t, "SOURCES", "a/SOURCES", "a/b/SOURCES", "a/b/c/SOURCES")
+	for _, c := range []struct {
+		maxDepth int
+		want     []string
+	}{
+		{0, []string{"SOURCES"}},
+		{1, []string{"SOURCES", "a/SOURCES"}},
+		{2, []string{"SOURCES", "a/SOURCES", "a/b/SOURCES"}},
+		{-1, []string{"SOURCES", "a/SOURCES", "a/b/SOURCES", "a/b/c/SOURCES"}},
+	} {
+		found, err := searchForManifests(root, []string{manifestName}, nil, c.maxDepth, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := relative(t, root, found); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("max depth %d: found %v, want %v", c.maxDepth, got, c.want)
+		}
+	}
+}
+
+func TestSearchForManifestsSymlinkCycle(t *testing.T) {
+	root := writeTree(t, "a/SOURCES", "b/SOURCES")
+	for link, target := range map[string]string{"a/loop": root, "b/a": filepath.Join(root, "a"), "c": filepath.Join(root, "b")} {
+		if err := os.Symlink(target, filepath.Join(root, filepath.FromSlash(link))); err != nil {
+			t.Skip(err)
+		}
+	}
+	done := make(chan []string)
+	go func() {
+		found, err := searchForManifests(root, []string{manifestName}, nil, -1, true)
+		if err != nil {
+			t.Error(err)
+		}
+		done <- found
+	}()
+	select {
+	case found := <-done:
+		want := []string{"a/SOURCES", "b/SOURCES"}
+		if got := relative(t, root, found); !reflect.DeepEqual(got, want) {
+			t.Errorf("found %v, want each directory searched once: %v", got, want)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("searching a symlink cycle did not end")
+	}
+}
+
+func TestSetupKeepsGitExcluded(t *testing.T) {
+	defer func() { excludes = nil }()
+	excludes = stringsFlag{"vendor"}
+	if err := setup(); err != nil {
+		t.Fatal(err)
+	}
+	if want := (stringsFlag{".git", "vendor"}); !reflect.DeepEqual(excludes, want) {
+		t.Errorf("excludes are %v, want %v", excludes, want)
+	}
+}
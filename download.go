@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/estk/sourcerer/sourcerer"
+)
+
+var outFlag = flag.String("out", ".", "directory the download command writes sources to")
+
+// runDownload downloads the archive of every source's pinned tag, or the
+// file at its url, into -out. Files that already exist are skipped, partial
+// downloads resumed.
+func runDownload(ctx context.Context, manifests []string) int {
+	if err := os.MkdirAll(*outFlag, 0755); err != nil {
+		errorf("%v", err)
+		return exitError
+	}
+	status := exitOK
+	for _, m := range manifests {
+		conf, err := parseConfig(m)
+		if err != nil {
+			errorf("%s: %v", m, err)
+			status = exitError
+			continue
+		}
+		for _, e := range sourcerer.AllPins(conf) {
+			if !selected(e) {
+				continue
+			}
+			if ctx.Err() != nil {
+				errorf("interrupted")
+				return exitError
+			}
+			if err := downloadEntry(ctx, e); err != nil {
+				errorf("%s: %v", m, err)
+				status = exitError
+			}
+		}
+	}
+	return status
+}
+
+// downloadEntry downloads the source of e into -out.
+func downloadEntry(ctx context.Context, e sourcerer.SourceEntry) error {
+	req, name, err := downloadRequest(ctx, e)
+	if err != nil {
+		return err
+	}
+	if name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		// The name is rendered from the manifest, which must not be able to
+		// write outside of -out.
+		return fmt.Errorf("%s: %q is not a file name; set a name, tag and ext without path separators", e.Label(), name)
+	}
+	dst := filepath.Join(*outFlag, name)
+	if _, err := os.Stat(dst); err == nil {
+		infof("%s already exists, skipping %s", dst, e.Label())
+		return nil
+	}
+	if err := downloadFile(req, dst); err != nil {
+		return fmt.Errorf("unable to download %s\n%v", e.Label(), err)
+	}
+	fmt.Printf("Downloaded %s to %s\n", e.Label(), dst)
+	return nil
+}
+
+// downloadRequest returns the request downloading e's source and the name of
+// the file to store it in: the url's last element for raw url sources,
+// otherwise the filename rendered with outFormat.
+func downloadRequest(ctx context.Context, e sourcerer.SourceEntry) (*http.Request, string, error) {
+	if e.URL != "" {
+		if e.VersionPath != "" {
+			return nil, "", fmt.Errorf("%s: the url of a source with a version_path is not an archive", e.Label())
+		}
+		u, err := url.Parse(e.URL)
+		if err != nil {
+			return nil, "", err
+		}
+		name := path.Base(u.Path)
+		if name == "/" || name == "." {
+			return nil, "", fmt.Errorf("%s: cannot name a file after the url", e.Label())
+		}
+		req, err := http.NewRequestWithContext(ctx, "GET", e.URL, nil)
+		return req, name, err
+	}
+	name, err := artifactName(e)
+	if err != nil {
+		return nil, "", err
+	}
+	req, err := checker.ArchiveRequest(ctx, e)
+	return req, name, err
+}
+
+// downloadFile writes the body of req to dst through dst.part, resuming a
+// previous partial download when the server supports ranges. The download is
+// only moved into place once its length matches the advertised one.
+func downloadFile(req *http.Request, dst string) error {
+	part := dst + ".part"
+	var offset int64
+	if fi, err := os.Stat(part); err == nil {
+		offset = fi.Size()
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	res, err := checker.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+		debugf("resuming %s at %d bytes", dst, offset)
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		offset = 0
+		flags |= os.O_TRUNC
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial download is likely complete but of unknown length;
+		// start over rather than trust it.
+		os.Remove(part)
+		req.Header.Del("Range")
+		return downloadFile(req, dst)
+	default:
+		return fmt.Errorf("unexpected status %s from %s", res.Status, req.URL)
+	}
+
+	f, err := os.OpenFile(part, flags, 0644)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(f, res.Body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if want, cerr := strconv.ParseInt(res.Header.Get("Content-Length"), 10, 64); cerr == nil && n != want {
+		return fmt.Errorf("got %d of %d bytes from %s; run again to resume", n, want, req.URL)
+	}
+	debugf("downloaded %d bytes to %s", offset+n, dst)
+	return os.Rename(part, dst)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/estk/sourcerer/sourcerer"
+)
+
+func TestDownloadEntryRejectsPaths(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	defer func(old string) { *outFlag = old }(*outFlag)
+	*outFlag = out
+	defer func(old *sourcerer.Checker) { checker = old }(checker)
+	checker = sourcerer.NewChecker()
+
+	for _, e := range []sourcerer.SourceEntry{
+		{Repo: "github.com/org/evil", Name: "../../etc/evil", Tag: "v1.0.0"},
+		{Repo: "github.com/org/pkg", Name: "@scope/pkg", Tag: "v1.0.0"},
+		{Repo: "github.com/org/lib", Tag: "release/1.0"},
+		{Repo: "github.com/org/lib", Tag: "v1.0.0", Ext: "tar.gz/../../evil"},
+	} {
+		err := downloadEntry(context.Background(), e)
+		if err == nil || !strings.Contains(err.Error(), "not a file name") {
+			t.Errorf("%s: got %v, want the name to be rejected", e.Label(), err)
+		}
+	}
+	if entries, err := ioutil.ReadDir(dir); err != nil || len(entries) != 0 {
+		t.Errorf("got %v in %s, want nothing written: %v", entries, dir, err)
+	}
+}
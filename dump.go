@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/estk/sourcerer/sourcerer"
+	"gopkg.in/yaml.v2"
+)
+
+// runConfigDump prints each manifest's Config as sourcerer resolves it: with
+// the defaults merged into every source, environment variables expanded and
+// the provider, source and versioning filled in where they were left to
+// their defaults. No requests are made.
+func runConfigDump(ctx context.Context, manifests []string) int {
+	status := exitOK
+	for i, m := range manifests {
+		conf, err := parseConfig(m)
+		if err != nil {
+			errorf("%s: %v", m, err)
+			status = exitError
+			continue
+		}
+		conf.Defaults = sourcerer.SourceEntry{}
+		for j, e := range conf.Sources {
+			conf.Sources[j] = e.Resolved()
+		}
+		out, err := yaml.Marshal(conf)
+		if err != nil {
+			errorf("%s: %v", m, err)
+			status = exitError
+			continue
+		}
+		if i > 0 {
+			fmt.Println("---")
+		}
+		fmt.Printf("# %s\n%s", m, out)
+	}
+	return status
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/estk/sourcerer/sourcerer"
+)
+
+// filters holds the -filter patterns; an entry is checked only if it matches
+// one of them, or if there are none.
+var filters stringsFlag
+
+// regexpFilterPrefix marks a -filter pattern as a regexp rather than a glob.
+const regexpFilterPrefix = "re:"
+
+func init() {
+	flag.Var(&filters, "filter", "only check sources whose repo, name or url matches this glob, or regexp when prefixed with "+regexpFilterPrefix+"; may be repeated")
+}
+
+// validateFilters checks that every -filter pattern is well formed.
+func validateFilters() error {
+	for _, f := range filters {
+		var err error
+		if strings.HasPrefix(f, regexpFilterPrefix) {
+			_, err = regexp.Compile(strings.TrimPrefix(f, regexpFilterPrefix))
+		} else {
+			_, err = path.Match(f, "")
+		}
+		if err != nil {
+			return fmt.Errorf("invalid -filter %q: %v", f, err)
+		}
+	}
+	return nil
+}
+
+// selected reports whether e passes the -filter patterns.
+func selected(e sourcerer.SourceEntry) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	for _, f := range filters {
+		for _, s := range []string{e.Repo, e.Name, e.URL} {
+			if s != "" && filterMatch(f, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func filterMatch(f, s string) bool {
+	if strings.HasPrefix(f, regexpFilterPrefix) {
+		re, err := regexp.Compile(strings.TrimPrefix(f, regexpFilterPrefix))
+		return err == nil && re.MatchString(s)
+	}
+	ok, _ := path.Match(f, s)
+	return ok
+}
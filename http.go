@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/estk/sourcerer/sourcerer"
+)
+
+var (
+	insecureSkipVerifyFlag = flag.Bool("insecure-skip-verify", false, "do not verify TLS certificates, e.g. of a GitHub Enterprise instance with a self-signed certificate")
+	rateFlag               = flag.Float64("rate", 0, "maximum number of requests per second (default 10 with a GitHub token, 2 without)")
+)
+
+// newTransport returns the transport of the shared client. Proxies are taken
+// from $HTTP_PROXY, $HTTPS_PROXY and $NO_PROXY; with insecure, certificates
+// are not verified.
+func newTransport(insecure bool) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyFromEnvironment
+	if insecure {
+		t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return t
+}
+
+// rateLimitPreflight queries the rate limit of the default GitHub API before
+// the GitHub sources of manifests are checked, logging the budget and warning
+// when it is too small for them, or failing with -fail-on-rate-limit.
+// Manifests that fail to parse are left to be reported by the check itself.
+// Nothing is queried when checking against a -snapshot.
+func rateLimitPreflight(ctx context.Context, manifests []string) error {
+	if checker.Snapshot != nil {
+		return nil
+	}
+	needed := 0
+	for _, m := range manifests {
+		conf, err := parseConfig(m)
+		if err != nil {
+			continue
+		}
+		for _, e := range sourcerer.AllPins(conf) {
+			if e.URL == "" && e.Resolved().Provider == sourcerer.ProviderGitHub && selected(e) {
+				needed++
+			}
+		}
+	}
+	if needed == 0 {
+		return nil
+	}
+	rl, err := checker.RateLimit(ctx)
+	if err != nil {
+		debugf("unable to query rate limit: %v", err)
+		return nil
+	}
+	reset := rl.Reset.Format(time.RFC1123)
+	infof("GitHub API rate limit: %d of %d requests remaining, resets at %s", rl.Remaining, rl.Limit, reset)
+	if rl.Remaining >= needed {
+		return nil
+	}
+	if *failOnRateLimitFlag {
+		return fmt.Errorf("only %d GitHub API requests remain for %d sources, resets at %s", rl.Remaining, needed, reset)
+	}
+	warnf("only %d GitHub API requests remain for %d sources, some will not be checked; resets at %s", rl.Remaining, needed, reset)
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/estk/sourcerer/sourcerer"
+)
+
+var levelNames = map[sourcerer.Level]string{
+	sourcerer.LevelError: "ERROR",
+	sourcerer.LevelWarn:  "WARN",
+	sourcerer.LevelInfo:  "INFO",
+	sourcerer.LevelDebug: "DEBUG",
+}
+
+// verbosity is raised by each -v/-verbose flag; errors and warnings are
+// always logged.
+var (
+	verbosity countFlag
+	logger    = log.New(os.Stderr, "", 0)
+)
+
+func init() {
+	flag.Var(&verbosity, "v", "increase log verbosity; repeat for debug output")
+	flag.Var(&verbosity, "verbose", "same as -v")
+}
+
+func logf(level sourcerer.Level, format string, args ...interface{}) {
+	if int(level) > int(sourcerer.LevelWarn)+int(verbosity) {
+		return
+	}
+	logger.Printf("%s %s", levelNames[level], fmt.Sprintf(format, args...))
+}
+
+func debugf(format string, args ...interface{}) { logf(sourcerer.LevelDebug, format, args...) }
+func infof(format string, args ...interface{})  { logf(sourcerer.LevelInfo, format, args...) }
+func warnf(format string, args ...interface{})  { logf(sourcerer.LevelWarn, format, args...) }
+func errorf(format string, args ...interface{}) { logf(sourcerer.LevelError, format, args...) }
+
+// countFlag is a flag.Value counting how many times a boolean flag was given.
+// An explicit value such as -v=2 sets the count.
+type countFlag int
+
+func (c *countFlag) String() string { return strconv.Itoa(int(*c)) }
+
+func (c *countFlag) IsBoolFlag() bool { return true }
+
+func (c *countFlag) Set(v string) error {
+	if v == "true" {
+		*c++
+		return nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return err
+	}
+	*c = countFlag(n)
+	return nil
+}
@@ -1,236 +1,545 @@
 package main
 
 import (
-	"encoding/json"
-	"errors"
+	"context"
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
-	"regexp"
-	"strconv"
 	"strings"
-	"sync"
+	"syscall"
+	"text/tabwriter"
 
+	"github.com/estk/sourcerer/sourcerer"
 	"github.com/fatih/color"
-	"gopkg.in/yaml.v2"
 )
 
-var (
-	repoRE   = regexp.MustCompile("^github.com/([^/]*)/([^/]*)")
-	semverRE = regexp.MustCompile(`^\D*(?P<first>(\d+))(\.(?P<second>\d+))?(\.(?P<third>\d+))?(\.(?P<fourth>\d+))?(\.(?P<fifth>\d+))?$`)
+// Exit statuses of every command. Scripts depend on them, so they do not
+// change; -print-exit-codes lists them.
+const (
+	exitOK = 0
+	// exitFailed means a source fails the -fail-on policy.
+	exitFailed = 1
+	// exitError means a flag, manifest or source could not be handled, or
+	// a check was interrupted. Stopping -watch is not an error.
+	exitError = 2
+	// exitRateLimited means -fail-on-rate-limit found too few GitHub API
+	// requests left to check every source.
+	exitRateLimited = 3
 )
 
+// exitCodes describes the exit statuses for -print-exit-codes.
+var exitCodes = []struct {
+	code int
+	desc string
+}{
+	{exitOK, "every source passes the -fail-on policy, or -watch was stopped"},
+	{exitFailed, "a source fails the -fail-on policy, e.g. it is outdated"},
+	{exitError, "a flag, manifest or source could not be handled, or a check was interrupted"},
+	{exitRateLimited, "too few GitHub API requests are left, with -fail-on-rate-limit"},
+}
+
 const (
 	manifestName = "SOURCES"
 	outFormat    = "{{.Name}}-{{.Version}}.{{.Ext}}"
 )
 
-type SourceEntry struct {
-	Repo string
-	Tag  string
-	URL  string
+// defaults holds the settings checks use unless flags say otherwise.
+var defaults = sourcerer.NewChecker()
+
+var (
+	apiBaseFlag         = flag.String("api-base", "", "GitHub API base URL (defaults to $GITHUB_API_URL or https://api.github.com)")
+	tokenFlag           = flag.String("token", "", "GitHub token used to authenticate API requests (defaults to $GITHUB_TOKEN)")
+	retriesFlag         = flag.Int("retries", defaults.Retries, "number of times to retry a failed GitHub API request")
+	retryDelayFlag      = flag.Duration("retry-delay", defaults.RetryDelay, "initial delay between retries, doubled after each attempt")
+	timeoutFlag         = flag.Duration("timeout", defaults.Timeout, "timeout for each HTTP request and exec provider command")
+	formatFlag          = flag.String("format", "text", "output format: text, json, junit, lines (tab separated, one source per line) or table (aligned columns)")
+	concurrencyFlag     = flag.Int("concurrency", defaults.Concurrency, "maximum number of manifests and release lookups processed at once")
+	noColorFlag         = flag.Bool("no-color", false, "disable colored output (also disabled by $NO_COLOR or when stdout is not a terminal)")
+	cacheDirFlag        = flag.String("cache-dir", "", "directory to cache GitHub API responses in (defaults to the user cache directory)")
+	cacheTTLFlag        = flag.Duration("cache-ttl", 0, "use cached GitHub API responses younger than this without revalidating them; 0 always revalidates")
+	noCacheFlag         = flag.Bool("no-cache", false, "do not cache GitHub API responses")
+	flatFlag            = flag.Bool("flat", false, "print the results of all manifests as one table sorted by source instead of grouped by manifest")
+	quietFlag           = flag.Bool("quiet", false, "only report outdated sources and errors")
+	dryRunFlag          = flag.Bool("dry-run", false, "print the lookups that would be made without making any requests")
+	allowEmptyFlag      = flag.Bool("allow-empty", false, "do not warn about manifests without any sources")
+	strictFlag          = flag.Bool("strict", false, "reject manifests pinning a tag that is not a version of the source's versioning scheme")
+	noEnvFlag           = flag.Bool("no-env", false, "do not expand environment variables in manifests")
+	failOnRateLimitFlag = flag.Bool("fail-on-rate-limit", false, "exit with status 3 before checking when the GitHub API rate limit left is smaller than the number of GitHub sources")
+	maxPagesFlag        = flag.Int("max-pages", defaults.MaxPages, "maximum number of pages of tags or releases to fetch per source")
+	followSymlinksFlag  = flag.Bool("follow-symlinks", false, "also search directories symlinked below the roots, each directory only once")
+	maxDepthFlag        = flag.Int("max-depth", -1, "search at most this many directory levels below each root, 0 being the root only; -1 is unlimited")
+	minBumpFlag         = flag.String("min-bump", "patch", "only report newer versions that bump at least this part of the version: patch, minor or major")
+	failOnFlag          = flag.String("fail-on", "outdated", "exit with status 1 when sources are: outdated, unknown (outdated or unknown), or none")
+	repoFlag            = flag.String("repo", "", "check this repo at -tag instead of searching for manifests, e.g. github.com/owner/name")
+	tagFlag             = flag.String("tag", "", "the tag of -repo")
+	noVerifyFlag        = flag.Bool("no-verify", false, "do not download release assets to verify their sha256")
+	eolAPIFlag          = flag.String("eol-api", defaults.EOLAPI, "base URL of the endoflife.date API queried for sources with an eol_product")
+	explainFlag         = flag.Bool("explain", false, "print how the versions of each source were parsed and compared to stderr")
+
+	manifestNames stringsFlag
+	excludes      stringsFlag
+	rootFlags     stringsFlag
+	fileFlags     stringsFlag
+
+	// client is shared by all release lookups; its timeout is set from
+	// timeoutFlag in main.
+	client = &http.Client{}
+
+	// checker checks the sources of every manifest with the settings of the
+	// flags; it is set up in main.
+	checker *sourcerer.Checker
+)
+
+// bumpLevels maps the values of -min-bump to the bump levels they name.
+var bumpLevels = map[string]int{
+	"major": sourcerer.BumpMajor,
+	"minor": sourcerer.BumpMinor,
+	"patch": sourcerer.BumpPatch,
 }
-type Config struct {
-	Sources []SourceEntry
+
+func init() {
+	flag.Var(&manifestNames, "manifest-name", "glob pattern of manifest file names to check; may be repeated (default "+manifestName+")")
+	flag.BoolVar(quietFlag, "q", false, "shorthand for -quiet")
+	flag.Var(&rootFlags, "C", "directory to search for manifests, in addition to any arguments; may be repeated")
+	flag.Var(&rootFlags, "path", "same as -C")
+	flag.Var(&fileFlags, "file", "manifest to check instead of searching for them, - for stdin; may be repeated")
+	flag.Var(&excludes, "exclude", "glob pattern of directory names or paths to skip besides .git; may be repeated")
+}
+
+// commands maps each subcommand to the function running it, which returns the
+// process exit status. check is run when no subcommand is given.
+var commands = map[string]func(ctx context.Context, manifests []string) int{
+	"check":       runCheck,
+	"config-dump": runConfigDump,
+	"download":    runDownload,
+	"list":        runList,
+	"outdated":    runOutdated,
+	"render":      runRender,
+	"snapshot":    runSnapshot,
+	"update":      runUpdate,
+	"verify":      runVerify,
 }
 
 func main() {
-	flag.Parse()
-	root := flag.Arg(0)
-	if root == "" {
-		root = "."
+	flag.Usage = usage
+	args := os.Args[1:]
+	cmd := "check"
+	if len(args) > 0 {
+		if _, ok := commands[args[0]]; ok {
+			cmd, args = args[0], args[1:]
+		}
 	}
-
-	manifests := searchForManifests(root)
-	fmt.Println("Found manifests:")
-	fmt.Println(strings.Join(manifests, "\n"), "\n")
-	var wg sync.WaitGroup
-	wg.Add(len(manifests))
-	for _, m := range manifests {
-		go func(m string) {
-			handleManifest(m)
-			wg.Done()
-		}(m)
+	// -print-exit-codes is left out of the usage on purpose, it is for
+	// scripts to check the contract they rely on.
+	if printExitCodesFlag(args) {
+		for _, c := range exitCodes {
+			fmt.Printf("%d\t%s\n", c.code, c.desc)
+		}
+		os.Exit(exitOK)
 	}
-	wg.Wait()
-}
-
-func handleManifest(filename string) {
-	// find
-	conf, err := parseConfig(filename)
-	if err != nil {
-		panic(err)
+	flag.CommandLine.Parse(args)
+	if err := loadRunConfig(); err != nil {
+		errorf("%v", err)
+		os.Exit(exitError)
+	}
+	if err := setup(); err != nil {
+		errorf("%v", err)
+		os.Exit(exitError)
 	}
-	msgs, err := checkNewer(conf)
+	var manifests []string
+	ok := true
+	if cmd == "verify" {
+		// The arguments are the manifests themselves rather than roots.
+		if files := append(fileFlags, flag.Args()...); len(files) > 0 {
+			manifests, ok = manifestFiles(files)
+		}
+	} else {
+		manifests, ok = discover()
+	}
+	// ctx is canceled on the first SIGINT or SIGTERM; a second one kills the
+	// process as usual.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	closeOutput, err := openOutput()
 	if err != nil {
-		panic(err)
+		errorf("cannot write -output: %v", err)
+		os.Exit(exitError)
 	}
-
-	fmt.Println(strings.Join(msgs, "\n"))
+	status := commands[cmd](ctx, manifests)
+	if !ok && status == exitOK {
+		status = exitError
+	}
+	if err := closeOutput(ctx.Err() == nil); err != nil {
+		errorf("cannot write -output: %v", err)
+		status = exitError
+	}
+	os.Exit(status)
 }
 
-func searchForManifests(root string) []string {
-	manifests := []string{}
-	visit := func(path string, f os.FileInfo, err error) error {
-		if strings.HasSuffix(path, manifestName) && !f.IsDir() {
-			manifests = append(manifests, path)
+// printExitCodesFlag reports whether -print-exit-codes is among the flags of
+// args, which end where flag parsing stops: at the first argument that is not
+// a flag or a flag's value, or after --.
+func printExitCodesFlag(args []string) bool {
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" || len(a) < 2 || a[0] != '-' {
+			return false
+		}
+		name := strings.TrimPrefix(a[1:], "-")
+		if name == "print-exit-codes" {
+			return true
+		}
+		if strings.Contains(name, "=") {
+			continue
+		}
+		if f := flag.CommandLine.Lookup(name); f != nil {
+			if b, ok := f.Value.(interface{ IsBoolFlag() bool }); !ok || !b.IsBoolFlag() {
+				// The next argument is the flag's value.
+				i++
+			}
 		}
-		return err
-	}
-	err := filepath.Walk(root, visit)
-	if err != nil {
-		panic(err)
 	}
-	return manifests
+	return false
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), `Usage: sourcerer [command] [flags] [root...]
+
+Commands:
+  check        check every source for a newer version (the default)
+  config-dump  print each manifest as resolved, with defaults, environment
+               variables and providers applied, without checking it
+  download     download the archive of each source's pinned version into -out
+  list         list discovered manifests and their sources without checking them
+  outdated     print only the outdated sources, the biggest bumps first
+  render       print the artifact filename of each source's pinned version
+  snapshot     print the latest version of each source as a -snapshot file
+  update       rewrite the tag of outdated sources to the latest version;
+               with -dry-run only print the changes
+  verify       check the manifests given as arguments, e.g. by a pre-commit
+               hook, printing a line for each source failing -fail-on
+
+Flags:
+`)
+	flag.PrintDefaults()
 }
 
-func checkEntry(e SourceEntry) (string, error) {
-	if len(e.URL) != 0 {
-		return fmt.Sprintf("Raw url specified, cannot check for currency: %s", e.URL), nil
+// setup validates the flags and applies them to the shared state used by
+// every command.
+func setup() error {
+	client.Timeout = *timeoutFlag
+	client.Transport = newTransport(*insecureSkipVerifyFlag)
+	if *insecureSkipVerifyFlag {
+		warnf("-insecure-skip-verify is set: TLS certificates are not verified, so responses may be forged")
 	}
-	owner, gitrepo, err := parseRepo(e.Repo)
-	if err != nil {
-		return "", err
+	if *concurrencyFlag < 1 {
+		return fmt.Errorf("-concurrency must be at least 1")
 	}
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/latest", owner, gitrepo)
-	res, err := http.Get(url)
-	if err != nil {
-		return "", fmt.Errorf("There was an error retrieving the latest release for %s\n%v", e.Repo, err)
+	if *rateFlag < 0 {
+		return fmt.Errorf("-rate must not be negative")
 	}
-	bodyBs, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return "", fmt.Errorf("unable to read body of url %s\n%v", e.URL, err)
+	checker = &sourcerer.Checker{
+		Client:      client,
+		Token:       *tokenFlag,
+		APIBase:     *apiBaseFlag,
+		Retries:     *retriesFlag,
+		RetryDelay:  *retryDelayFlag,
+		Rate:        *rateFlag,
+		Concurrency: *concurrencyFlag,
+		MaxPages:    *maxPagesFlag,
+		CacheTTL:    *cacheTTLFlag,
+		Timeout:     *timeoutFlag,
+		NoVerify:    *noVerifyFlag,
+		EOLAPI:      *eolAPIFlag,
+		Strict:      *strictFlag,
+		NoEnv:       *noEnvFlag,
+		Filter:      selected,
+		Logf:        logf,
+		Checked:     func() { runProgress.step() },
 	}
-	var gitObj map[string]*json.RawMessage
-	err = json.Unmarshal(bodyBs, &gitObj)
-	if err != nil {
-		return "", fmt.Errorf("unable to parse body of url %s\n%v\n body:\n%s", e.URL, err, string(bodyBs))
+	if !*noCacheFlag {
+		checker.CacheDir = *cacheDirFlag
+		if checker.CacheDir == "" {
+			checker.CacheDir = defaultCacheDir()
+		}
 	}
-
-	if gitObj["name"] == nil {
-		m := color.YellowString("Unable to check currency, latest release undefined for %s", e.Repo)
-		return m, nil
+	if *explainFlag {
+		checker.Explain = func(explanation string) { logger.Print(explanation) }
 	}
-	var tag string
-	err = json.Unmarshal(*gitObj["name"], tag)
-	rel, err := compareSemver(e.Tag, tag)
-	if err != nil {
-		return "", err
+	switch *formatFlag {
+	case "text":
+		if *reportFileFlag != "" {
+			return fmt.Errorf("-report-file requires -format json, junit, lines or table")
+		}
+	case "json", "lines", "table":
+	case "junit":
+		if *watchFlag > 0 {
+			return fmt.Errorf("-format junit cannot be used with -watch")
+		}
+	default:
+		return fmt.Errorf("unknown format %q, expected text, json, junit, lines or table", *formatFlag)
 	}
-	if rel < 0 {
-		m := color.RedString(`There is a newer version of: %s
-			have: %s
-			latest: %s`, e.Repo, e.Tag, tag)
-		return m, nil
-	} else {
-		m := color.GreenString("Up to date: %s", e.Repo)
-		return m, nil
+	if *notifyFormatFlag != "json" && *notifyFormatFlag != "slack" {
+		return fmt.Errorf("unknown -notify-format %q, expected json or slack", *notifyFormatFlag)
+	}
+	if *templateFlag != "" {
+		if *formatFlag != "text" || *flatFlag {
+			return fmt.Errorf("-template cannot be used with -flat or a -format other than text")
+		}
+		var err error
+		if resultTemplate, err = parseResultTemplate(*templateFlag); err != nil {
+			return err
+		}
+	}
+	if *outputFlag != "" && *watchFlag > 0 {
+		return fmt.Errorf("-output cannot be used with -watch")
+	}
+	// color.NoColor already defaults to true when stdout is not a terminal.
+	interactive := *formatFlag == "text" || (*formatFlag == "table" && *reportFileFlag == "")
+	if _, ok := os.LookupEnv("NO_COLOR"); ok || *noColorFlag || !interactive || *outputFlag != "" {
+		color.NoColor = true
+	}
+	switch *failOnFlag {
+	case "outdated", "unknown", "none":
+	default:
+		return fmt.Errorf("unknown -fail-on value %q, expected outdated, unknown or none", *failOnFlag)
+	}
+	minBump, ok := bumpLevels[*minBumpFlag]
+	if !ok {
+		return fmt.Errorf("unknown -min-bump value %q, expected patch, minor or major", *minBumpFlag)
+	}
+	checker.MinBump = minBump
+	if *maxPagesFlag < 1 {
+		return fmt.Errorf("-max-pages must be at least 1")
+	}
+	if *watchFlag < 0 {
+		return fmt.Errorf("-watch must not be negative")
 	}
+	if *metricsAddrFlag != "" && *watchFlag == 0 {
+		return fmt.Errorf("-metrics-addr requires -watch")
+	}
+	if len(manifestNames) == 0 {
+		manifestNames = stringsFlag{manifestName}
+	}
+	hasGit := false
+	for _, p := range excludes {
+		hasGit = hasGit || p == ".git"
+	}
+	if !hasGit {
+		excludes = append(stringsFlag{".git"}, excludes...)
+	}
+	for _, p := range append(manifestNames, excludes...) {
+		if _, err := filepath.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", p, err)
+		}
+	}
+	if (*repoFlag == "") != (*tagFlag == "") {
+		return fmt.Errorf("-repo and -tag must be given together")
+	}
+	if *repoFlag != "" && (len(fileFlags) > 0 || len(rootFlags) > 0) {
+		return fmt.Errorf("-repo cannot be used with -file or -C")
+	}
+	if *snapshotFlag != "" {
+		var err error
+		if checker.Snapshot, err = sourcerer.LoadSnapshot(*snapshotFlag); err != nil {
+			return err
+		}
+	}
+	return validateFilters()
 }
 
-func checkNewer(config Config) ([]string, error) {
-	msgs := []string{}
-	for _, e := range config.Sources {
-		m, err := checkEntry(e)
-		if err != nil {
-			return msgs, err
-		}
-		msgs = append(msgs, m)
+// defaultCacheDir returns the directory responses are cached in when
+// -cache-dir is not given.
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
 	}
-	return msgs, nil
+	return filepath.Join(dir, "sourcerer")
 }
 
-func mkSemver(s string) ([]int, error) {
-	names := semverRE.SubexpNames()
-	m := semverRE.FindStringSubmatch(s)
-	out := []int{}
-	for i, n := range names {
-		if n != "" && len(m) > i && m[i] != "" {
-			part, err := strconv.ParseInt(m[i], 10, 32)
-			if err != nil {
-				return out, fmt.Errorf("could not parse %s as semver\n%v", s, err)
-			}
-			if part < 0 {
-				return out, fmt.Errorf("could not parse %s as semver, one part was < 0", s)
-			}
-			out = append(out, int(part))
+// discover returns the manifests under the roots given on the command line.
+// ok is false if some roots could not be fully searched; those errors have
+// been logged.
+func discover() (manifests []string, ok bool) {
+	if *repoFlag != "" {
+		return []string{adhocManifest}, true
+	}
+	if len(fileFlags) > 0 {
+		return manifestFiles(fileFlags)
+	}
+	roots, err := searchRoots(append(flag.Args(), rootFlags...))
+	if err != nil {
+		errorf("%v", err)
+		return nil, false
+	}
+	ok = true
+	for _, root := range roots {
+		found, err := searchForManifests(root, manifestNames, excludes, *maxDepthFlag, *followSymlinksFlag)
+		if err != nil {
+			errorf("%v", err)
+			ok = false
 		}
+		manifests = append(manifests, found...)
 	}
-	return out, nil
+	manifests = dedupe(manifests)
+	infof("found %d manifests under %s", len(manifests), strings.Join(roots, ", "))
+	return manifests, ok
 }
 
-func compareSemver(x, y string) (int, error) {
-	var as, bs []int
-	xs, err1 := mkSemver(x)
-	ys, err2 := mkSemver(y)
-	if err1 != nil || err2 != nil {
-		return 0, fmt.Errorf("Error comparing semver:\n%v\n%v", err1, err2)
+// runCheck checks every source of manifests and reports the results. It exits
+// exitFailed when a source fails the -fail-on policy, exitError when a
+// manifest could not be checked and exitRateLimited when -fail-on-rate-limit
+// stops it before checking. With -watch it keeps checking until interrupted instead.
+func runCheck(ctx context.Context, manifests []string) int {
+	if *dryRunFlag {
+		if err := printDryRun(manifests); err != nil {
+			return exitError
+		}
+		return exitOK
 	}
-
-	// So we range over all parts
-	if len(xs) >= len(ys) {
-		as = xs
-		bs = ys
-	} else {
-		as = ys
-		bs = xs
+	if err := rateLimitPreflight(ctx, manifests); err != nil {
+		errorf("%v", err)
+		return exitRateLimited
+	}
+	warnConflicts(manifests)
+	if *watchFlag > 0 {
+		return runWatch(ctx, manifests)
 	}
-	for i, a := range as {
-		var b int
-		if i >= len(bs) {
-			b = 0 // Nothing left to compare
-		} else {
-			b = bs[i]
+	if *formatFlag == "text" && !*quietFlag && *repoFlag == "" && resultTemplate == nil {
+		fmt.Println("Found manifests:")
+		fmt.Println(strings.Join(manifests, "\n"))
+		fmt.Println()
+	}
+	var counts tally
+	var emit func([]sourcerer.Result)
+	if *formatFlag == "text" && !*flatFlag {
+		emit = func(results []sourcerer.Result) {
+			runProgress.clear()
+			printManifest(results)
+			runProgress.draw()
 		}
+	}
+	startProgress(manifests)
+	results, errs := checkManifests(ctx, manifests, &counts, emit)
+	runProgress.clear()
+	if ctx.Err() != nil {
+		errorf("interrupted")
+		return exitError
+	}
+	if *formatFlag == "text" && *flatFlag {
+		printFlat(results)
+	}
 
-		if a > b {
-			return 1, nil
-		} else if a < b {
-			return -1, nil
+	if *formatFlag != "text" {
+		if err := writeReport(manifests, results, errs); err != nil {
+			errorf("%v", err)
+			return exitError
 		}
 	}
-	return 0, nil
-}
 
-func parseRepo(repo string) (string, string, error) {
-	match := repoRE.FindStringSubmatch(repo)
-	if len(match) != 3 {
-		return "", "", fmt.Errorf("Could not parse: %s, found: %v", repo, match)
+	if *formatFlag == "text" {
+		fmt.Fprintln(os.Stderr, counts.summary(len(manifests)))
 	}
-	return match[1], match[2], nil
-}
-
-func parseConfig(filename string) (Config, error) {
-	var config Config
 
-	data, err := ioutil.ReadFile(filename)
-	if err != nil {
-		return config, err
+	failed := false
+	if err := notify(ctx, results); err != nil {
+		errorf("%v", err)
+		failed = true
 	}
-
-	err = yaml.Unmarshal(data, &config)
-	if err != nil {
-		return config, fmt.Errorf("Invalid yaml\n%v", err)
+	for i, err := range errs {
+		if err != nil {
+			errorf("%s: %v", manifests[i], err)
+			failed = true
+		}
 	}
-	err = validateConfig(config)
-	if err != nil {
-		return config, fmt.Errorf("Invalid config\n%v", err)
+	if failed {
+		return exitError
+	}
+	for _, rs := range results {
+		for _, r := range rs {
+			if shouldFail(r.Status) {
+				return exitFailed
+			}
+		}
 	}
+	return exitOK
+}
 
-	return config, err
+// runList prints each manifest followed by its sources, without making any
+// requests.
+func runList(ctx context.Context, manifests []string) int {
+	status := exitOK
+	for _, m := range manifests {
+		conf, err := parseConfig(m)
+		if err != nil {
+			errorf("%s: %v", m, err)
+			status = exitError
+			continue
+		}
+		fmt.Println(m)
+		for _, e := range conf.Sources {
+			if !selected(e) {
+				continue
+			}
+			if len(e.Tags) > 0 {
+				fmt.Printf("\t%s %s\n", e.Label(), strings.Join(e.Tags, ", "))
+			} else if e.Tag != "" {
+				fmt.Printf("\t%s %s\n", e.Label(), e.Tag)
+			} else {
+				fmt.Printf("\t%s\n", e.Label())
+			}
+		}
+	}
+	return status
 }
 
-func validateConfig(config Config) error {
-	for _, e := range config.Sources {
-		if len(e.URL) != 0 && len(e.Repo) != 0 {
-			return errors.New("cannot define a url and a repo; pick one")
+// printDryRun prints the lookup each entry of manifests would make. Manifests
+// that fail to parse are reported on stderr and the last such error returned.
+func printDryRun(manifests []string) error {
+	var failed error
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "MANIFEST\tSOURCE\tTAG\tURL")
+	for _, m := range manifests {
+		conf, err := parseConfig(m)
+		if err != nil {
+			errorf("%s: %v", m, err)
+			failed = err
+			continue
 		}
-		if len(e.Repo) != 0 && len(e.Tag) == 0 {
-			return errors.New("when defining a repo you must also define a tag to pull")
+		for _, e := range sourcerer.AllPins(conf) {
+			if !selected(e) {
+				continue
+			}
+			url := e.URL
+			if url == "" {
+				url, err = checker.LookupURL(e)
+				if err != nil {
+					url = err.Error()
+				}
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", m, e.Label(), e.Tag, url)
 		}
 	}
+	w.Flush()
+	return failed
+}
+
+// stringsFlag is a flag.Value collecting every occurrence of a repeatable
+// string flag.
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(v string) error {
+	*s = append(*s, v)
 	return nil
 }
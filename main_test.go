@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeGitHub serves a GitHub API whose repos all have latest as their latest
+// release and v1.0.0 as an older one, with remaining requests left of the
+// rate limit.
+func fakeGitHub(t *testing.T, latest string, remaining int) *httptest.Server {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.URL.Path == "/rate_limit":
+			fmt.Fprintf(w, `{"resources": {"core": {"limit": 60, "remaining": %d, "reset": 0}}}`, remaining)
+		case strings.HasSuffix(req.URL.Path, "/releases/latest"):
+			fmt.Fprintf(w, `{"tag_name": %q}`, latest)
+		case strings.HasSuffix(req.URL.Path, "/releases/tags/"+latest), strings.HasSuffix(req.URL.Path, "/releases/tags/v1.0.0"):
+			fmt.Fprintf(w, `{"tag_name": %q, "published_at": "2024-01-01T00:00:00Z"}`, path.Base(req.URL.Path))
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// runCommand runs cmd on a manifest pinning tag, with the flags in args and
+// the GitHub API at api, and returns its exit status. Flags are reset to
+// their defaults afterwards.
+func runCommand(t *testing.T, ctx context.Context, api, cmd, tag string, args ...string) int {
+	t.Cleanup(func() {
+		flag.VisitAll(func(f *flag.Flag) {
+			if _, ok := f.Value.(*stringsFlag); !ok && !strings.HasPrefix(f.Name, "test.") {
+				f.Value.Set(f.DefValue)
+			}
+		})
+	})
+	host := strings.TrimPrefix(api, "http://")
+	manifest := filepath.Join(t.TempDir(), manifestName)
+	data := fmt.Sprintf("sources:\n  - repo: %s/org/lib\n    tag: %s\n", host, tag)
+	if err := ioutil.WriteFile(manifest, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	args = append([]string{"-api-base", api, "-no-cache", "-retries", "0", "-rate", "1000"}, args...)
+	if err := flag.CommandLine.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	if err := setup(); err != nil {
+		t.Fatal(err)
+	}
+	return commands[cmd](ctx, []string{manifest})
+}
+
+func TestExitCodes(t *testing.T) {
+	api := fakeGitHub(t, "v2.0.0", 60).URL
+	limited := fakeGitHub(t, "v2.0.0", 0).URL
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, c := range []struct {
+		desc string
+		ctx  context.Context
+		api  string
+		tag  string
+		args []string
+		want int
+	}{
+		{"up to date", context.Background(), api, "v2.0.0", nil, exitOK},
+		{"outdated", context.Background(), api, "v1.0.0", nil, exitFailed},
+		{"outdated with -fail-on none", context.Background(), api, "v1.0.0", []string{"-fail-on", "none"}, exitOK},
+		{"missing tag", context.Background(), api, "v9.9.9", nil, exitFailed},
+		{"invalid manifest", context.Background(), api, "[", nil, exitError},
+		{"interrupted", canceled, api, "v1.0.0", nil, exitError},
+		{"stopped watch", canceled, api, "v1.0.0", []string{"-watch", "1h"}, exitOK},
+		{"rate limited", context.Background(), limited, "v1.0.0", []string{"-fail-on-rate-limit"}, exitRateLimited},
+		{"rate limited without -fail-on-rate-limit", context.Background(), limited, "v2.0.0", nil, exitOK},
+	} {
+		t.Run(c.desc, func(t *testing.T) {
+			if got := runCommand(t, c.ctx, c.api, "check", c.tag, c.args...); got != c.want {
+				t.Errorf("got exit status %d, want %d", got, c.want)
+			}
+		})
+	}
+}
+
+func TestExitCodesDocumented(t *testing.T) {
+	for i, c := range exitCodes {
+		if c.code != i || c.desc == "" {
+			t.Errorf("exit status %d is documented as %d %q", i, c.code, c.desc)
+		}
+	}
+	if len(exitCodes) != exitRateLimited+1 {
+		t.Errorf("got %d documented exit statuses, want %d", len(exitCodes), exitRateLimited+1)
+	}
+	for _, want := range []string{"interrupted", "-watch was stopped"} {
+		found := false
+		for _, c := range exitCodes {
+			found = found || strings.Contains(c.desc, want)
+		}
+		if !found {
+			t.Errorf("no exit status is documented as %q", want)
+		}
+	}
+}
+
+func TestPrintExitCodesFlag(t *testing.T) {
+	for _, c := range []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"-print-exit-codes"}, true},
+		{[]string{"--print-exit-codes"}, true},
+		{[]string{"-q", "-format", "json", "-print-exit-codes"}, true},
+		{[]string{"-format=json", "-print-exit-codes"}, true},
+		{[]string{"root", "-print-exit-codes"}, false},
+		{[]string{"--", "-print-exit-codes"}, false},
+		{[]string{"-tag", "-print-exit-codes"}, false},
+		{[]string{"-q"}, false},
+	} {
+		if got := printExitCodesFlag(c.args); got != c.want {
+			t.Errorf("printExitCodesFlag(%q) = %v, want %v", c.args, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/estk/sourcerer/sourcerer"
+	"github.com/fatih/color"
+)
+
+// message renders r as the text output shows it, colored by status.
+func message(r sourcerer.Result) string {
+	name := resultLabel(r)
+	if r.Branch != "" {
+		name = fmt.Sprintf("%s (%s)", name, r.Branch)
+	} else if r.Track != "" {
+		name = fmt.Sprintf("%s (%s)", name, r.Track)
+	} else if r.CurrentTag == "" && r.Asset != "" {
+		name = fmt.Sprintf("%s (%s)", name, r.Asset)
+	}
+	switch r.Status {
+	case sourcerer.StatusRaw:
+		return fmt.Sprintf("Raw url specified, cannot check for currency: %s", r.URL)
+	case sourcerer.StatusPinned:
+		if r.LatestTag != "" {
+			return color.CyanString("Pinned: %s at %s (latest: %s)", name, r.CurrentTag, r.LatestTag)
+		}
+		return color.CyanString("Pinned: %s at %s", name, r.CurrentTag)
+	case sourcerer.StatusUnknown:
+		return color.YellowString("Unable to check currency of %s: %s", name, r.Detail)
+	case sourcerer.StatusNotFound:
+		msg := fmt.Sprintf("Repository or %s not found: %s", r.Detail, name)
+		if r.Hint != "" {
+			msg += "\n\t\t\t" + r.Hint
+		}
+		return color.RedString("%s", msg)
+	case sourcerer.StatusMissing:
+		return color.RedString(`Pinned version no longer exists upstream: %s
+			have: %s
+			latest: %s`, name, r.CurrentTag, r.LatestTag)
+	case sourcerer.StatusMismatch:
+		return color.RedString(`Release asset changed: %s %s of %s
+			want sha256: %s
+			got sha256: %s`, r.Asset, r.CurrentTag, name, r.WantSHA256, r.GotSHA256)
+	case sourcerer.StatusEOL:
+		eol := "end-of-life"
+		if r.EOLDate != "" {
+			eol += " since " + r.EOLDate
+		}
+		return color.RedString(`Release cycle is end-of-life: %s
+			have: %s (cycle %s, %s)
+			latest: %s`, name, r.CurrentTag, r.EOLCycle, eol, r.LatestTag)
+	case sourcerer.StatusAdvanced:
+		return color.RedString(`Branch has new commits: %s
+			have: %s
+			latest: %s`, name, r.CurrentTag, r.LatestTag)
+	case sourcerer.StatusOutdated:
+		var msg string
+		if sourcerer.IsFloor(r.CurrentTag) {
+			msg = fmt.Sprintf(`Latest version is below the minimum for: %s
+			want: %s
+			latest: %s`, name, r.CurrentTag, r.LatestTag)
+		} else if sourcerer.IsConstraint(r.CurrentTag) {
+			msg = fmt.Sprintf(`Latest version is out of range for: %s
+			want: %s
+			latest: %s`, name, r.CurrentTag, r.LatestTag)
+		} else {
+			msg = fmt.Sprintf(`There is a newer version of: %s
+			have: %s
+			latest: %s`, name, r.CurrentTag, r.LatestTag)
+		}
+		if r.LatestPublished != "" {
+			msg += fmt.Sprintf("\n\t\t\tlatest is %d days old (published %s)", r.LatestAgeDays, strings.SplitN(r.LatestPublished, "T", 2)[0])
+		}
+		return color.RedString("%s", msg)
+	}
+	switch {
+	case sourcerer.IsConstraint(r.CurrentTag):
+		return color.GreenString("In range: %s", name)
+	case r.Detail != "":
+		return color.GreenString("Up to date %s: %s (latest %s)", r.Detail, name, r.LatestTag)
+	}
+	return color.GreenString("Up to date: %s", name)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/estk/sourcerer/sourcerer"
+)
+
+var metricsAddrFlag = flag.String("metrics-addr", "", "with -watch, serve Prometheus metrics on this address, e.g. :9090")
+
+// metrics holds the state of the last watch cycle for the Prometheus
+// endpoint. It is safe for concurrent use.
+type metrics struct {
+	mu       sync.Mutex
+	outdated map[[3]string]bool
+	errors   int
+}
+
+// update replaces the outdated gauges with those of a cycle's results and
+// counts its failed manifests as errors.
+func (m *metrics) update(results [][]sourcerer.Result, errs []error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.outdated = map[[3]string]bool{}
+	for _, rs := range results {
+		for _, r := range rs {
+			m.outdated[[3]string{r.Manifest, resultLabel(r), r.CurrentTag}] = r.Status == sourcerer.StatusOutdated
+		}
+	}
+	for _, err := range errs {
+		if err != nil {
+			m.errors++
+		}
+	}
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (m *metrics) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keys := make([][3]string, 0, len(m.outdated))
+	for k := range m.outdated {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		for n := range keys[i] {
+			if keys[i][n] != keys[j][n] {
+				return keys[i][n] < keys[j][n]
+			}
+		}
+		return false
+	})
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintln(w, "# HELP sourcerer_source_outdated Whether the source was outdated at the last check.")
+	fmt.Fprintln(w, "# TYPE sourcerer_source_outdated gauge")
+	for _, k := range keys {
+		v := 0
+		if m.outdated[k] {
+			v = 1
+		}
+		fmt.Fprintf(w, "sourcerer_source_outdated{manifest=\"%s\",repo=\"%s\",tag=\"%s\"} %d\n", escapeLabel(k[0]), escapeLabel(k[1]), escapeLabel(k[2]), v)
+	}
+	fmt.Fprintln(w, "# HELP sourcerer_check_errors_total Number of manifests that could not be checked.")
+	fmt.Fprintln(w, "# TYPE sourcerer_check_errors_total counter")
+	fmt.Fprintf(w, "sourcerer_check_errors_total %d\n", m.errors)
+}
+
+// serveMetrics starts serving m on addr in the background.
+func serveMetrics(addr string, m *metrics) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("unable to serve metrics\n%v", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m)
+	go func() {
+		if err := http.Serve(l, mux); err != nil {
+			errorf("metrics server stopped: %v", err)
+		}
+	}()
+	infof("serving metrics on %s/metrics", l.Addr())
+	return nil
+}
+
+// resultLabel identifies the source of r like SourceEntry.Label: its name,
+// repo or url.
+func resultLabel(r sourcerer.Result) string {
+	switch {
+	case r.Name != "":
+		return r.Name
+	case r.Repo != "":
+		return r.Repo
+	}
+	return r.URL
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapes v for use as a Prometheus label value.
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/estk/sourcerer/sourcerer"
+)
+
+func TestMetricsLabelPins(t *testing.T) {
+	m := &metrics{}
+	m.update([][]sourcerer.Result{{
+		{Manifest: "SOURCES", Repo: "github.com/org/server", CurrentTag: "v1.8.0", Status: sourcerer.StatusOutdated, Multi: true},
+		{Manifest: "SOURCES", Repo: "github.com/org/server", CurrentTag: "v2.3.0", Status: sourcerer.StatusUpToDate, Multi: true},
+	}}, nil)
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	for _, want := range []string{
+		`sourcerer_source_outdated{manifest="SOURCES",repo="github.com/org/server",tag="v1.8.0"} 1`,
+		`sourcerer_source_outdated{manifest="SOURCES",repo="github.com/org/server",tag="v2.3.0"} 0`,
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("metrics lack %s:\n%s", want, rec.Body)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/estk/sourcerer/sourcerer"
+)
+
+var (
+	notifyWebhookFlag = flag.String("notify-webhook", "", "POST a summary of sources needing attention to this URL at the end of a run")
+	notifyFormatFlag  = flag.String("notify-format", "json", "payload format of -notify-webhook: json or slack (an incoming webhook message)")
+)
+
+// notification is a source reported to -notify-webhook.
+type notification struct {
+	Manifest string           `json:"manifest"`
+	Repo     string           `json:"repo"`
+	Current  string           `json:"current"`
+	Latest   string           `json:"latest"`
+	Status   sourcerer.Status `json:"status"`
+}
+
+// notify posts the results needing attention among results to
+// -notify-webhook, if set. Nothing is posted when there are none.
+func notify(ctx context.Context, results [][]sourcerer.Result) error {
+	if *notifyWebhookFlag == "" {
+		return nil
+	}
+	ns := []notification{}
+	for _, rs := range results {
+		for _, r := range rs {
+			if needsAttention(r.Status) {
+				ns = append(ns, notification{r.Manifest, resultLabel(r), r.CurrentTag, r.LatestTag, r.Status})
+			}
+		}
+	}
+	if len(ns) == 0 {
+		return nil
+	}
+	var payload interface{} = struct {
+		Sources []notification `json:"sources"`
+	}{ns}
+	if *notifyFormatFlag == "slack" {
+		payload = struct {
+			Text string `json:"text"`
+		}{slackText(ns)}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", *notifyWebhookFlag, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to notify webhook\n%v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("unable to notify webhook: %s", res.Status)
+	}
+	debugf("notified webhook of %d sources", len(ns))
+	return nil
+}
+
+// slackText formats ns as the text of a Slack message.
+func slackText(ns []notification) string {
+	lines := []string{fmt.Sprintf("sourcerer found %d sources needing attention:", len(ns))}
+	for _, n := range ns {
+		if n.Latest != "" {
+			lines = append(lines, fmt.Sprintf("• `%s` (%s): %s, latest %s", n.Repo, n.Status, n.Current, n.Latest))
+		} else {
+			lines = append(lines, fmt.Sprintf("• `%s` (%s): %s", n.Repo, n.Status, n.Current))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/estk/sourcerer/sourcerer"
+)
+
+// bumpNames names the bump levels in the outdated table.
+var bumpNames = map[int]string{sourcerer.BumpMajor: "major", sourcerer.BumpMinor: "minor", sourcerer.BumpPatch: "patch", sourcerer.BumpUnknown: "-"}
+
+// runOutdated checks every source of manifests and prints only the outdated
+// ones as a table, the biggest bumps first: major, minor, patch and then
+// bumps without a level, such as those of calendar versions or out of range
+// constraints. It exits exitFailed when there are any.
+func runOutdated(ctx context.Context, manifests []string) int {
+	var counts tally
+	results, errs := checkManifests(ctx, manifests, &counts, nil)
+	if ctx.Err() != nil {
+		errorf("interrupted")
+		return exitError
+	}
+	status := exitOK
+	outdated := []sourcerer.Result{}
+	for i, rs := range results {
+		if errs[i] != nil {
+			errorf("%s: %v", manifests[i], errs[i])
+			status = exitError
+		}
+		for _, r := range rs {
+			if r.Status == sourcerer.StatusOutdated {
+				outdated = append(outdated, r)
+			}
+		}
+	}
+	if len(outdated) == 0 {
+		return status
+	}
+	sortByBump(outdated)
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SOURCE\tBUMP\tCURRENT\tLATEST\tMANIFEST")
+	for _, r := range outdated {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", resultLabel(r), bumpNames[r.Bump], r.CurrentTag, r.LatestTag, r.Manifest)
+	}
+	w.Flush()
+	if status == exitOK {
+		status = exitFailed
+	}
+	return status
+}
+
+// sortByBump sorts results by bump, the biggest first, and then by source and
+// manifest.
+func sortByBump(results []sourcerer.Result) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Bump != results[j].Bump {
+			return results[i].Bump < results[j].Bump
+		}
+		if li, lj := resultLabel(results[i]), resultLabel(results[j]); li != lj {
+			return li < lj
+		}
+		return results[i].Manifest < results[j].Manifest
+	})
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/estk/sourcerer/sourcerer"
+)
+
+func TestSortByBump(t *testing.T) {
+	results := []sourcerer.Result{
+		{Repo: "github.com/org/unknown", Bump: sourcerer.BumpUnknown},
+		{Repo: "github.com/org/patch", Bump: sourcerer.BumpPatch},
+		{Repo: "github.com/org/minor", Bump: sourcerer.BumpMinor},
+		{Repo: "github.com/org/b", Bump: sourcerer.BumpMajor},
+		{Repo: "github.com/org/a", Bump: sourcerer.BumpMajor},
+	}
+	sortByBump(results)
+	want := []string{"github.com/org/a", "github.com/org/b", "github.com/org/minor", "github.com/org/patch", "github.com/org/unknown"}
+	for i, r := range results {
+		if r.Repo != want[i] {
+			t.Errorf("result %d is %s, want %s", i, r.Repo, want[i])
+		}
+	}
+}
+
+func TestOutdatedExitCodes(t *testing.T) {
+	api := fakeGitHub(t, "v2.0.0", 60).URL
+	for _, c := range []struct {
+		desc string
+		tag  string
+		want int
+	}{
+		{"up to date", "v2.0.0", exitOK},
+		{"outdated", "v1.0.0", exitFailed},
+		{"invalid manifest", "[", exitError},
+	} {
+		t.Run(c.desc, func(t *testing.T) {
+			if got := runCommand(t, context.Background(), api, "outdated", c.tag); got != c.want {
+				t.Errorf("got exit status %d, want %d", got, c.want)
+			}
+		})
+	}
+}
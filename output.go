@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+var outputFlag = flag.String("output", "", "write the output of the run to this file instead of stdout, replacing it only once the run completes")
+
+// openOutput redirects stdout to a temporary file next to -output, if set.
+// The returned function moves the file into place when commit is true and
+// discards it otherwise, so an interrupted run leaves any earlier output
+// untouched.
+func openOutput() (func(commit bool) error, error) {
+	if *outputFlag == "" {
+		return func(bool) error { return nil }, nil
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(*outputFlag), "."+filepath.Base(*outputFlag)+".tmp-")
+	if err != nil {
+		return nil, err
+	}
+	stdout := os.Stdout
+	os.Stdout = tmp
+	return func(commit bool) error {
+		os.Stdout = stdout
+		err := tmp.Close()
+		if err == nil && commit {
+			mode := os.FileMode(0644)
+			if fi, err := os.Stat(*outputFlag); err == nil {
+				mode = fi.Mode()
+			}
+			err = os.Chmod(tmp.Name(), mode)
+		}
+		if err == nil && commit {
+			err = os.Rename(tmp.Name(), *outputFlag)
+		}
+		if err != nil || !commit {
+			os.Remove(tmp.Name())
+		}
+		return err
+	}, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/estk/sourcerer/sourcerer"
+)
+
+var noProgressFlag = flag.Bool("no-progress", false, "do not show how many sources have been checked while checking")
+
+// progress shows on stderr how many of the sources of a run have been
+// checked. A nil *progress shows nothing, so callers need not check whether
+// it is enabled.
+type progress struct {
+	mu          sync.Mutex
+	done, total int
+}
+
+// runProgress is the progress of the current check, nil when not shown.
+var runProgress *progress
+
+// isTerminal reports whether f is a terminal rather than a pipe or file.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// startProgress enables progress for a text run of manifests when both stdout
+// and stderr are terminals and -no-progress is not set.
+func startProgress(manifests []string) {
+	if *noProgressFlag || *formatFlag != "text" || *outputFlag != "" || !isTerminal(os.Stdout) || !isTerminal(os.Stderr) {
+		return
+	}
+	total := 0
+	for _, m := range manifests {
+		conf, err := parseConfig(m)
+		if err != nil {
+			continue
+		}
+		for _, e := range sourcerer.AllPins(conf) {
+			if selected(e) {
+				total++
+			}
+		}
+	}
+	runProgress = &progress{total: total}
+	runProgress.draw()
+}
+
+// step counts one more source as checked.
+func (p *progress) step() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	p.redraw()
+}
+
+// draw shows the progress line.
+func (p *progress) draw() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.redraw()
+}
+
+func (p *progress) redraw() {
+	fmt.Fprintf(os.Stderr, "\r\033[KChecked %d/%d sources", p.done, p.total)
+}
+
+// clear removes the progress line, so that other output can be printed.
+func (p *progress) clear() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprint(os.Stderr, "\r\033[K")
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/estk/sourcerer/sourcerer"
+)
+
+var (
+	outTemplate = template.Must(template.New("out").Parse(outFormat))
+
+	// leadingVRE matches the v of versions written like v1.2.3.
+	leadingVRE = regexp.MustCompile("^v[0-9]")
+)
+
+// artifactName renders outFormat for the pinned version of e. The name
+// defaults to the last element of the repo, the version is the tag's version
+// without a leading v and the extension defaults to tar.gz.
+func artifactName(e sourcerer.SourceEntry) (string, error) {
+	name := e.Name
+	if name == "" && e.Repo != "" {
+		name = path.Base(e.Repo)
+	}
+	if name == "" {
+		return "", fmt.Errorf("%s: a name is required to render a filename", e.Label())
+	}
+	if e.Tag == "" || sourcerer.IsConstraint(e.Tag) {
+		return "", fmt.Errorf("%s: a pinned tag is required to render a filename", e.Label())
+	}
+	version, ok := e.Version(e.Tag)
+	if !ok {
+		return "", fmt.Errorf("%s: tag %s does not follow the tag scheme", e.Label(), e.Tag)
+	}
+	if leadingVRE.MatchString(version) {
+		version = version[1:]
+	}
+	ext := e.Ext
+	if ext == "" {
+		ext = "tar.gz"
+	}
+	var buf bytes.Buffer
+	err := outTemplate.Execute(&buf, struct{ Name, Version, Ext string }{name, version, strings.TrimPrefix(ext, ".")})
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// runRender prints the artifact filename of every source of manifests.
+// Sources a filename cannot be rendered for are reported on stderr.
+func runRender(ctx context.Context, manifests []string) int {
+	status := exitOK
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "MANIFEST\tSOURCE\tFILE")
+	for _, m := range manifests {
+		conf, err := parseConfig(m)
+		if err != nil {
+			errorf("%s: %v", m, err)
+			status = exitError
+			continue
+		}
+		for _, e := range sourcerer.AllPins(conf) {
+			if !selected(e) {
+				continue
+			}
+			file, err := artifactName(e)
+			if err != nil {
+				errorf("%s: %v", m, err)
+				status = exitError
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", m, e.Label(), file)
+		}
+	}
+	w.Flush()
+	return status
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/estk/sourcerer/sourcerer"
+	"github.com/fatih/color"
+)
+
+var reportFileFlag = flag.String("report-file", "", "with -format json, junit, lines or table, write the report to this file instead of stdout")
+
+// writeReport writes the results of a check in the json, junit, lines or table
+// -format to -report-file, or stdout.
+func writeReport(manifests []string, results [][]sourcerer.Result, errs []error) error {
+	w := io.Writer(os.Stdout)
+	if *reportFileFlag != "" {
+		f, err := os.Create(*reportFileFlag)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if *formatFlag == "junit" {
+		return writeJUnit(w, manifests, results, errs)
+	}
+	all := []sourcerer.Result{}
+	for _, rs := range results {
+		for _, r := range rs {
+			if shown(r) {
+				all = append(all, r)
+			}
+		}
+	}
+	if *formatFlag == "lines" {
+		return writeLines(w, all)
+	}
+	if *formatFlag == "table" {
+		return writeTable(w, all)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(all)
+}
+
+// writeLines writes one line per result for -format lines: the source, its
+// current and latest versions and its status, separated by tabs. Empty fields
+// are written as - so that every line has four fields. This format is meant
+// for scripts and is kept stable.
+func writeLines(w io.Writer, results []sourcerer.Result) error {
+	for _, r := range results {
+		fields := []string{resultLabel(r), r.CurrentTag, r.LatestTag, string(r.Status)}
+		for i, f := range fields {
+			if f == "" {
+				fields[i] = "-"
+			}
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(fields, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// statusColors colors the status column of -format table; statuses not
+// listed are red and raw ones not colored.
+var statusColors = map[sourcerer.Status]func(string, ...interface{}) string{
+	sourcerer.StatusUpToDate: color.GreenString,
+	sourcerer.StatusUnknown:  color.YellowString,
+	sourcerer.StatusPinned:   color.CyanString,
+	sourcerer.StatusRaw:      fmt.Sprintf,
+}
+
+// writeTable writes results as aligned columns for -format table, coloring
+// only the status. When the terminal w writes to is too narrow, sources are
+// truncated to fit; when w is not a terminal, only if $COLUMNS is set and too
+// narrow. The status and age are padded here rather than by tabwriter, which
+// would count the color codes as width.
+func writeTable(w io.Writer, results []sourcerer.Result) error {
+	header := []string{"MANIFEST", "SOURCE", "CURRENT", "LATEST", "STATUS", "AGE"}
+	rows := [][]string{header}
+	for _, r := range results {
+		age := ""
+		if r.LatestPublished != "" {
+			age = fmt.Sprintf("%dd", r.LatestAgeDays)
+		}
+		rows = append(rows, []string{r.Manifest, resultLabel(r), r.CurrentTag, r.LatestTag, string(r.Status), age})
+	}
+	const padding = 2
+	widths := make([]int, len(header))
+	for _, row := range rows {
+		for i, cell := range row {
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+	columns, ok := terminalWidth(w)
+	if !ok {
+		if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+			columns, ok = n, true
+		}
+	}
+	if ok {
+		room := columns
+		for i, width := range widths {
+			if i != 1 {
+				room -= width + padding
+			}
+		}
+		if room < 10 {
+			room = 10
+		}
+		for _, row := range rows[1:] {
+			if len(row[1]) > room {
+				row[1] = row[1][:room-3] + "..."
+			}
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, padding, ' ', 0)
+	for i, row := range rows {
+		status := fmt.Sprintf("%-*s", widths[4]+padding, row[4])
+		if i > 0 {
+			paint, ok := statusColors[results[i-1].Status]
+			if !ok {
+				paint = color.RedString
+			}
+			status = paint("%s", row[4]) + status[len(row[4]):]
+		}
+		line := strings.Join(row[:4], "\t") + "\t" + strings.TrimRight(status+row[5], " ")
+		if _, err := fmt.Fprintln(tw, line); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+type junitSuite struct {
+	XMLName  xml.Name    `xml:"testsuite"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	Skipped   *junitProblem `xml:"skipped,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+// writeJUnit writes a JUnit testsuite with a testcase per source, named after
+// it and classed by its manifest. Sources that fail the -fail-on policy are
+// failures, raw and pinned ones skipped, and manifests that could not be fully
+// checked an error each.
+func writeJUnit(w io.Writer, manifests []string, results [][]sourcerer.Result, errs []error) error {
+	suite := junitSuite{Name: "sourcerer"}
+	for i, rs := range results {
+		for _, r := range rs {
+			c := junitCase{ClassName: r.Manifest, Name: resultLabel(r)}
+			switch {
+			case shouldFail(r.Status) || r.Status == sourcerer.StatusUnknown:
+				c.Failure = &junitProblem{Message: string(r.Status), Type: string(r.Status), Body: message(r)}
+				suite.Failures++
+			case r.Status == sourcerer.StatusRaw || r.Status == sourcerer.StatusPinned:
+				c.Skipped = &junitProblem{Message: message(r)}
+				suite.Skipped++
+			}
+			suite.Cases = append(suite.Cases, c)
+		}
+		if errs[i] != nil {
+			suite.Cases = append(suite.Cases, junitCase{
+				ClassName: manifests[i],
+				Name:      manifests[i],
+				Error:     &junitProblem{Message: "could not be checked", Body: errs[i].Error()},
+			})
+			suite.Errors++
+		}
+	}
+	suite.Tests = len(suite.Cases)
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// runConfigName is the run configuration file looked for in the first root
+// when -config is not given.
+const runConfigName = ".sourcerer.yaml"
+
+var configFlag = flag.String("config", "", "YAML file setting defaults for these flags (defaults to "+runConfigName+" in the first root)")
+
+// loadRunConfig applies the run configuration file to every flag not given on
+// the command line. The file maps flag names to values, with a list for
+// repeatable flags:
+//
+//	token: ${SOURCERER_TOKEN}
+//	timeout: 10s
+//	concurrency: 4
+//	exclude: [vendor]
+//
+// A missing default file is not an error; a missing -config file is.
+func loadRunConfig() error {
+	path := *configFlag
+	if path == "" {
+		roots := append(flag.Args(), rootFlags...)
+		root := "."
+		if len(roots) > 0 {
+			// verify takes manifests rather than roots; their run config is
+			// the one in the current directory.
+			if fi, err := os.Stat(roots[0]); err == nil && fi.IsDir() {
+				root = roots[0]
+			}
+		}
+		path = filepath.Join(root, runConfigName)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil
+		}
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var values map[string]interface{}
+	err = yaml.Unmarshal(data, &values)
+	if err != nil {
+		return fmt.Errorf("Invalid yaml in %s\n%v", path, err)
+	}
+
+	set := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for name, v := range values {
+		if flag.Lookup(name) == nil || name == "config" {
+			return fmt.Errorf("%s: unknown option %q", path, name)
+		}
+		if set[name] {
+			continue
+		}
+		vs, ok := v.([]interface{})
+		if !ok {
+			vs = []interface{}{v}
+		}
+		for _, v := range vs {
+			if err := flag.Set(name, os.ExpandEnv(fmt.Sprint(v))); err != nil {
+				return fmt.Errorf("%s: invalid value for %s\n%v", path, name, err)
+			}
+		}
+	}
+	debugf("loaded run configuration from %s", path)
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/estk/sourcerer/sourcerer"
+)
+
+var snapshotFlag = flag.String("snapshot", "", "check against the latest versions recorded in this snapshot file instead of looking them up, for offline runs")
+
+// runSnapshot looks up the latest version of every selected source of
+// manifests, as check would but without comparing it with the pinned tag,
+// and prints them as a -snapshot file. Sources that could not be looked up
+// are logged and left out, and make it exit 2.
+func runSnapshot(ctx context.Context, manifests []string) int {
+	if checker.Snapshot != nil {
+		errorf("snapshot cannot be used with -snapshot")
+		return exitError
+	}
+	status := exitOK
+	entries := []sourcerer.SourceEntry{}
+	for _, m := range manifests {
+		conf, err := parseConfig(m)
+		if err != nil {
+			errorf("%s: %v", m, err)
+			status = exitError
+			continue
+		}
+		for _, e := range sourcerer.AllPins(conf) {
+			if selected(e) && (e.URL == "" || e.VersionPath != "") && e.LatestAsset == "" {
+				entries = append(entries, e)
+			}
+		}
+	}
+
+	checker.ResetMemo()
+	s := sourcerer.Snapshot{Time: time.Now().UTC(), Sources: map[string]sourcerer.SnapshotSource{}}
+	sem := make(chan struct{}, *concurrencyFlag)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, e := range entries {
+		wg.Add(1)
+		go func(e sourcerer.SourceEntry) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			latest, err := checker.LookupLatest(ctx, e)
+			<-sem
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errorf("%v", err)
+				status = exitError
+				return
+			}
+			s.Sources[sourcerer.SnapshotKey(e)] = sourcerer.SnapshotSource{Provider: e.Resolved().Provider, Latest: latest}
+		}(e)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		errorf("interrupted")
+		return exitError
+	}
+
+	out, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		errorf("%v", err)
+		return exitError
+	}
+	fmt.Printf("%s\n", out)
+	return status
+}
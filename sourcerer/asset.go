@@ -0,0 +1,126 @@
+package sourcerer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var sha256RE = regexp.MustCompile("^[0-9a-fA-F]{64}$")
+
+// assetSHA256 downloads the release asset e.Asset of the release tagged
+// e.Tag and returns its hex encoded SHA256.
+func (c *Checker) assetSHA256(ctx context.Context, e SourceEntry) (string, error) {
+	repoURL, err := c.githubRepoURL(e)
+	if err != nil {
+		return "", err
+	}
+	_, assetURL, err := c.releaseAsset(ctx, repoURL+"/releases/tags/"+url.PathEscape(e.Tag), e.Asset)
+	if err != nil {
+		return "", err
+	}
+	if assetURL == "" {
+		return "", fmt.Errorf("release %s of %s has no asset %s", e.Tag, e.Repo, e.Asset)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", assetURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/octet-stream")
+	c.setGitHubAuth(req)
+	res, err := c.doWithRetry(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s downloading %s", res.Status, assetURL)
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, res.Body); err != nil {
+		return "", fmt.Errorf("unable to download %s\n%v", assetURL, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// releaseAsset returns the tag of the release at releaseURL and the API URL
+// of its asset called name, empty if it has none.
+func (c *Checker) releaseAsset(ctx context.Context, releaseURL, name string) (tag, assetURL string, err error) {
+	body, err := c.githubFetch(ctx, releaseURL)
+	if err != nil {
+		return "", "", err
+	}
+	var release struct {
+		TagName string `json:"tag_name"`
+		Assets  []struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"assets"`
+	}
+	err = json.Unmarshal(body, &release)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to parse body of url %s\n%v\n body:\n%s", releaseURL, err, string(body))
+	}
+	for _, a := range release.Assets {
+		if a.Name == name {
+			assetURL = a.URL
+		}
+	}
+	return release.TagName, assetURL, nil
+}
+
+// checkLatestAsset checks whether the latest release of e has the asset
+// e.LatestAsset, for sources publishing rolling artifacts rather than
+// versions: r is up to date if it does and not found otherwise.
+func (c *Checker) checkLatestAsset(ctx context.Context, e SourceEntry, r Result) (Result, error) {
+	r.Asset = e.LatestAsset
+	repoURL, err := c.githubRepoURL(e)
+	if err != nil {
+		return r, err
+	}
+	tag, assetURL, err := c.releaseAsset(ctx, repoURL+"/releases/latest", e.LatestAsset)
+	if rlErr, limited := err.(*rateLimitError); limited {
+		r.Status = StatusUnknown
+		r.Detail = rlErr.Error()
+		return r, nil
+	}
+	if nfErr, missing := err.(*notFoundError); missing {
+		r.Status = StatusNotFound
+		r.Detail, r.Hint = "release", nfErr.hint()
+		return r, nil
+	}
+	if err != nil {
+		return r, fmt.Errorf("There was an error retrieving the latest release of %s\n%v", e.Label(), err)
+	}
+	r.LatestTag = tag
+	if assetURL == "" {
+		r.Status = StatusNotFound
+		r.Detail = "latest release asset"
+		return r, nil
+	}
+	r.Status = StatusUpToDate
+	return r, nil
+}
+
+// verifyAsset checks the release asset of e against its recorded SHA256,
+// marking r as a mismatch if it changed.
+func (c *Checker) verifyAsset(ctx context.Context, e SourceEntry, r *Result) error {
+	sum, err := c.assetSHA256(ctx, e)
+	if err != nil {
+		return fmt.Errorf("There was an error verifying %s of %s\n%v", e.Asset, e.Label(), err)
+	}
+	c.debugf("sha256 of %s of %s is %s", e.Asset, e.Label(), sum)
+	if !strings.EqualFold(sum, e.SHA256) {
+		r.Status = StatusMismatch
+		r.Asset, r.WantSHA256, r.GotSHA256 = e.Asset, e.SHA256, sum
+	}
+	return nil
+}
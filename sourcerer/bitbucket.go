@@ -0,0 +1,130 @@
+package sourcerer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const defaultBitbucketAPIBase = "https://api.bitbucket.org/2.0"
+
+// bitbucketProvider looks up tags with the Bitbucket Cloud API. Bitbucket has
+// no releases, so entries are always compared against the highest semver tag.
+// Repos are written as bitbucket.org/workspace/repo.
+type bitbucketProvider struct{}
+
+// bitbucketAPIBase returns the API base URL to use for e, preferring the
+// entry's own setting over $BITBUCKET_API_URL.
+func bitbucketAPIBase(e SourceEntry) string {
+	base := e.APIBase
+	if base == "" {
+		base = os.Getenv("BITBUCKET_API_URL")
+	}
+	if base == "" {
+		base = defaultBitbucketAPIBase
+	}
+	if !strings.Contains(base, "://") {
+		base = "https://" + base
+	}
+	return strings.TrimSuffix(base, "/")
+}
+
+// bitbucketRepoURL returns the API URL of e's repo.
+func bitbucketRepoURL(e SourceEntry) (string, error) {
+	base := bitbucketAPIBase(e)
+	host := "bitbucket.org"
+	if u, err := url.Parse(base); err == nil && u.Host != "api.bitbucket.org" {
+		host = u.Host
+	}
+	workspace, repo, err := parseRepo(e.Repo, host)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/repositories/%s/%s", base, workspace, repo), nil
+}
+
+func (bitbucketProvider) lookupURL(c *Checker, e SourceEntry) (string, error) {
+	repoURL, err := bitbucketRepoURL(e)
+	if err != nil {
+		return "", err
+	}
+	return repoURL + "/refs/tags?pagelen=100", nil
+}
+
+func (bitbucketProvider) latest(ctx context.Context, c *Checker, e SourceEntry, url string) (string, bool, error) {
+	names := []string{}
+	for pages := 0; url != "" && pages < c.MaxPages; pages++ {
+		body, err := c.bitbucketFetch(ctx, url)
+		if err != nil {
+			return "", false, err
+		}
+		var page struct {
+			Values []struct {
+				Name string `json:"name"`
+			} `json:"values"`
+			Next string `json:"next"`
+		}
+		err = json.Unmarshal(body, &page)
+		if err != nil {
+			return "", false, fmt.Errorf("unable to parse body of url %s\n%v\n body:\n%s", url, err, string(body))
+		}
+		for _, v := range page.Values {
+			names = append(names, v.Name)
+		}
+		url = page.Next
+	}
+	tag, ok := highestVersion(e, names)
+	return tag, ok, nil
+}
+
+func (bitbucketProvider) tagExists(ctx context.Context, c *Checker, e SourceEntry, tag string) (bool, error) {
+	repoURL, err := bitbucketRepoURL(e)
+	if err != nil {
+		return false, err
+	}
+	ref := repoURL + "/refs/tags/" + url.PathEscape(tag)
+	req, err := bitbucketRequest(ctx, ref)
+	if err != nil {
+		return false, err
+	}
+	res, err := c.doWithRetry(req)
+	if err != nil {
+		return false, err
+	}
+	res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+	return false, fmt.Errorf("unable to look up %s: %s", ref, res.Status)
+}
+
+// bitbucketRequest returns a GET request for url, authenticated with
+// $BITBUCKET_TOKEN, or with $BITBUCKET_USERNAME and the app password in
+// $BITBUCKET_APP_PASSWORD, when they are set.
+func bitbucketRequest(ctx context.Context, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token := os.Getenv("BITBUCKET_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if user := os.Getenv("BITBUCKET_USERNAME"); user != "" {
+		req.SetBasicAuth(user, os.Getenv("BITBUCKET_APP_PASSWORD"))
+	}
+	return req, nil
+}
+
+func (c *Checker) bitbucketFetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := bitbucketRequest(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return c.fetch(req)
+}
@@ -0,0 +1,81 @@
+package sourcerer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cacheEntry is a cached response body along with the ETag it was served
+// with, the URL of the next page of results if any, and when it was last
+// fetched or revalidated.
+type cacheEntry struct {
+	ETag    string    `json:"etag"`
+	Next    string    `json:"next,omitempty"`
+	Body    []byte    `json:"body"`
+	Fetched time.Time `json:"fetched"`
+}
+
+// fresh reports whether e is young enough, per ttl, to be used without
+// revalidating it.
+func (e *cacheEntry) fresh(ttl time.Duration) bool {
+	return ttl > 0 && time.Since(e.Fetched) < ttl
+}
+
+func (c *Checker) cachePath(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.CacheDir, hex.EncodeToString(sum[:])+".json")
+}
+
+// loadCache returns the cached response for url, or nil if there is none.
+func (c *Checker) loadCache(url string) *cacheEntry {
+	if c.CacheDir == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(c.cachePath(url))
+	if err != nil {
+		return nil
+	}
+	var e cacheEntry
+	if err := json.Unmarshal(data, &e); err != nil {
+		c.debugf("ignoring corrupt cache entry for %s: %v", url, err)
+		return nil
+	}
+	return &e
+}
+
+// storeCache caches the response for url. The entry is written to a
+// temporary file first so concurrent readers never see a partial entry.
+// Failing to cache is not fatal and only logged.
+func (c *Checker) storeCache(url string, e cacheEntry) {
+	if c.CacheDir == "" {
+		return
+	}
+	data, err := json.Marshal(e)
+	if err == nil {
+		err = os.MkdirAll(c.CacheDir, 0755)
+	}
+	var tmp *os.File
+	if err == nil {
+		tmp, err = ioutil.TempFile(c.CacheDir, "tmp-")
+	}
+	if err == nil {
+		_, err = tmp.Write(data)
+		if cerr := tmp.Close(); err == nil {
+			err = cerr
+		}
+		if err == nil {
+			err = os.Rename(tmp.Name(), c.cachePath(url))
+		}
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}
+	if err != nil {
+		c.warnf("unable to cache response for %s: %v", url, err)
+	}
+}
@@ -0,0 +1,99 @@
+package sourcerer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	versioningSemver  = "semver"
+	versioningCalver  = "calver"
+	versioningLexical = "lexical"
+)
+
+// calverRE matches calendar versions such as 2024.03, 24.3.1 or 2024.03.15.2,
+// a year followed by a month and optionally a day or micro and a further
+// micro part.
+var calverRE = regexp.MustCompile(`^\D*(\d{2}|\d{4})\.(\d{1,2})(?:\.(\d+))?(?:\.(\d+))?$`)
+
+// mkCalver parses a calendar version into its numeric parts, ignoring leading
+// zeros. The month must be between 1 and 12.
+func mkCalver(s string) ([]int, error) {
+	m := calverRE.FindStringSubmatch(s)
+	if m == nil {
+		return nil, fmt.Errorf("could not parse %s as calver", s)
+	}
+	parts := []int{}
+	for _, p := range m[1:] {
+		if p == "" {
+			continue
+		}
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %s as calver\n%v", s, err)
+		}
+		parts = append(parts, n)
+	}
+	if parts[1] < 1 || parts[1] > 12 {
+		return nil, fmt.Errorf("could not parse %s as calver, month %d is out of range", s, parts[1])
+	}
+	return parts, nil
+}
+
+// compareCalver returns -1, 0 or 1 as the calendar version x is lower than,
+// equal to or higher than y. Missing parts count as 0, so 2024.01 equals
+// 2024.1.0.
+func compareCalver(x, y string) (int, error) {
+	xs, err1 := mkCalver(x)
+	ys, err2 := mkCalver(y)
+	if err1 != nil || err2 != nil {
+		return 0, fmt.Errorf("Error comparing calver:\n%v\n%v", err1, err2)
+	}
+	for i := 0; i < len(xs) || i < len(ys); i++ {
+		var a, b int
+		if i < len(xs) {
+			a = xs[i]
+		}
+		if i < len(ys) {
+			b = ys[i]
+		}
+		if a > b {
+			return 1, nil
+		} else if a < b {
+			return -1, nil
+		}
+	}
+	return 0, nil
+}
+
+// parseVersion checks that v is a version of e's versioning scheme. Any
+// non-empty string is a lexical version.
+func (e SourceEntry) parseVersion(v string) error {
+	if e.Versioning == versioningLexical {
+		if v == "" {
+			return fmt.Errorf("empty version")
+		}
+		return nil
+	}
+	if e.Versioning == versioningCalver {
+		_, err := mkCalver(v)
+		return err
+	}
+	_, err := mkSemver(v)
+	return err
+}
+
+// compareVersions compares the versions x and y according to e's versioning
+// scheme, like CompareSemver. Lexical versions, such as build numbers or
+// dates padded to a fixed width, are compared as strings.
+func (e SourceEntry) compareVersions(x, y string) (int, error) {
+	if e.Versioning == versioningLexical {
+		return strings.Compare(x, y), nil
+	}
+	if e.Versioning == versioningCalver {
+		return compareCalver(x, y)
+	}
+	return CompareSemver(x, y)
+}
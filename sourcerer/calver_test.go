@@ -0,0 +1,53 @@
+package sourcerer
+
+import "testing"
+
+func TestCompareCalver(t *testing.T) {
+	for _, c := range []struct {
+		x, y string
+		want int
+	}{
+		{"2024.01", "2024.1", 0},
+		{"2024.01", "2024.1.0", 0},
+		{"2024.03.1", "2024.3.01", 0},
+		{"2023.12", "2024.01", -1},
+		{"2023.12.31", "2024.1.1", -1},
+		{"2024.01", "2023.12.9", 1},
+		{"2024.10", "2024.9", 1},
+	} {
+		if got, err := compareCalver(c.x, c.y); err != nil || got != c.want {
+			t.Errorf("compareCalver(%s, %s) = %d, %v; want %d", c.x, c.y, got, err, c.want)
+		}
+	}
+	if _, err := compareCalver("2024.13", "2024.1"); err == nil {
+		t.Error("compareCalver accepted month 13")
+	}
+}
+
+func TestCompareLexical(t *testing.T) {
+	e := SourceEntry{Versioning: versioningLexical}
+	for _, c := range []struct {
+		x, y string
+		want int
+	}{
+		{"20240101", "20240102", -1},
+		{"build-0042", "build-0041", 1},
+		{"r9", "r10", 1},
+		{"nightly", "nightly", 0},
+	} {
+		if got, err := e.compareVersions(c.x, c.y); err != nil || got != c.want {
+			t.Errorf("compareVersions(%s, %s) = %d, %v; want %d", c.x, c.y, got, err, c.want)
+		}
+	}
+	for _, v := range []string{"nightly", "2024-13-99", "r10"} {
+		if err := e.parseVersion(v); err != nil {
+			t.Errorf("parseVersion(%s) = %v, want any non-empty version accepted", v, err)
+		}
+	}
+	if err := e.parseVersion(""); err == nil {
+		t.Error("parseVersion accepted an empty lexical version")
+	}
+	if bump := e.bumpLevel("r1", "r2"); bump != BumpUnknown {
+		t.Errorf("got bump %d between lexical versions, want BumpUnknown", bump)
+	}
+}
@@ -0,0 +1,314 @@
+package sourcerer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CheckEntry checks e against its latest version, whether its release cycle
+// is end-of-life and, unless c.NoVerify is set, its release asset against the
+// recorded SHA256. Pinned entries are still checked, but reported as pinned
+// rather than by how they compare.
+func (c *Checker) CheckEntry(ctx context.Context, e SourceEntry) (Result, error) {
+	r, err := c.checkLatest(ctx, e)
+	if err != nil {
+		return r, err
+	}
+	if e.EOLProduct != "" && c.Snapshot == nil && (r.Status == StatusUpToDate || r.Status == StatusOutdated) {
+		if err := c.checkEOL(ctx, e, &r); err != nil {
+			return r, err
+		}
+	}
+	if e.Asset != "" && !c.NoVerify && c.Snapshot == nil && r.Status != StatusMissing && r.Status != StatusNotFound {
+		if err := c.verifyAsset(ctx, e, &r); err != nil {
+			return r, err
+		}
+	}
+	if !e.Pinned || r.Status == StatusMissing || r.Status == StatusMismatch || r.Status == StatusNotFound || r.Status == StatusEOL {
+		return r, nil
+	}
+	r.Status = StatusPinned
+	return r, nil
+}
+
+func (c *Checker) checkLatest(ctx context.Context, e SourceEntry) (Result, error) {
+	r := Result{Repo: e.Repo, Name: e.Name, URL: e.URL, CurrentTag: e.Tag, Track: e.Track}
+	if len(e.URL) != 0 && len(e.VersionPath) == 0 {
+		r.Status = StatusRaw
+		return r, nil
+	}
+	r.Provider = e.Resolved().Provider
+	name := e.Label()
+	p, err := providerFor(e)
+	if err != nil {
+		return r, err
+	}
+	if e.Branch != "" {
+		return c.checkBranch(ctx, e, p, r)
+	}
+	if e.LatestAsset != "" {
+		if c.Snapshot != nil {
+			r.Status = StatusUnknown
+			r.Detail = "latest release assets cannot be checked against a snapshot"
+			return r, nil
+		}
+		return c.checkLatestAsset(ctx, e, r)
+	}
+	if c.Snapshot != nil {
+		return c.checkSnapshot(ctx, e, p, r)
+	}
+	url, err := p.lookupURL(c, e)
+	if err != nil {
+		return r, err
+	}
+	tag, ok, err := c.memoLatest(ctx, p, e, url)
+	if rlErr, limited := err.(*rateLimitError); limited {
+		r.Status = StatusUnknown
+		r.Detail = rlErr.Error()
+		return r, nil
+	}
+	if nfErr, missing := err.(*notFoundError); missing {
+		r.Status = StatusNotFound
+		r.Detail, r.Hint = e.sourceKind(), nfErr.hint()
+		return r, nil
+	}
+	if err != nil {
+		return r, fmt.Errorf("There was an error retrieving the latest version of %s\n%v", name, err)
+	}
+	if !ok {
+		r.Status = StatusUnknown
+		r.Detail = fmt.Sprintf("latest %s undefined", e.sourceKind())
+		return r, nil
+	}
+	r.LatestTag = tag
+	c.debugf("latest %s of %s is %s", e.sourceKind(), name, tag)
+	if tc, ok := p.(tagChecker); ok && !IsConstraint(e.Tag) && e.Tag != tag {
+		exists, err := tc.tagExists(ctx, c, e, e.Tag)
+		if rlErr, limited := err.(*rateLimitError); limited {
+			r.Status = StatusUnknown
+			r.Detail = rlErr.Error()
+			return r, nil
+		}
+		if err != nil {
+			return r, fmt.Errorf("There was an error looking up %s %s of %s\n%v", e.sourceKind(), e.Tag, name, err)
+		}
+		if !exists {
+			r.Status = StatusMissing
+			return r, nil
+		}
+	}
+	return c.compareLatest(ctx, e, p, r)
+}
+
+// checkSnapshot is checkLatest for checks against c.Snapshot, taking the
+// latest version of e from it. Whether e's tag exists upstream is not checked.
+func (c *Checker) checkSnapshot(ctx context.Context, e SourceEntry, p provider, r Result) (Result, error) {
+	tag, ok := c.Snapshot.latest(e)
+	if !ok {
+		r.Status = StatusUnknown
+		r.Detail = fmt.Sprintf("no %q in the snapshot", SnapshotKey(e))
+		return r, nil
+	}
+	r.LatestTag = tag
+	return c.compareLatest(ctx, e, p, r)
+}
+
+// compareLatest sets the status of r by how e's tag compares with
+// r.LatestTag, explaining it to c.Explain.
+func (c *Checker) compareLatest(ctx context.Context, e SourceEntry, p provider, r Result) (res Result, err error) {
+	if c.Explain != nil {
+		defer func() { c.Explain(explanation(e, res, err)) }()
+	}
+	tag := r.LatestTag
+	current, latest := e.Tag, tag
+	if v, ok := e.Version(e.Tag); ok {
+		current = v
+	}
+	if v, ok := e.Version(tag); ok {
+		latest = v
+	}
+	if err := e.parseVersion(latest); err != nil {
+		r.Status = StatusUnknown
+		r.Detail = fmt.Sprintf("unparseable upstream version %s", tag)
+		return r, nil
+	}
+	if IsConstraint(e.Tag) {
+		cons, err := parseConstraint(e.Tag)
+		if err != nil {
+			return r, err
+		}
+		allowed, err := cons.allows(latest)
+		if err != nil {
+			return r, err
+		}
+		if allowed {
+			r.Status = StatusUpToDate
+		} else {
+			r.Status, r.Bump = StatusOutdated, BumpUnknown
+		}
+		return r, nil
+	}
+	rel, err := e.compareVersions(current, latest)
+	if err != nil {
+		return r, err
+	}
+	if rel < 0 && !e.bumpReported(current, latest, c.MinBump) {
+		r.Status = StatusUpToDate
+		r.Detail = fmt.Sprintf("within -min-bump %s", bumpNames[c.MinBump])
+	} else if rel < 0 {
+		r.Status, r.Bump = StatusOutdated, e.bumpLevel(current, latest)
+		if d, ok := p.(releaseDater); ok && c.Snapshot == nil {
+			c.addAge(ctx, &r, d, e)
+		}
+	} else {
+		r.Status = StatusUpToDate
+	}
+	return r, nil
+}
+
+// addAge records when r's latest version was published and how long ago in
+// r. Failing to find out is only logged, the result stands without it.
+func (c *Checker) addAge(ctx context.Context, r *Result, d releaseDater, e SourceEntry) {
+	published, err := d.published(ctx, c, e, r.LatestTag)
+	if err != nil || published.IsZero() {
+		c.debugf("could not find when %s of %s was published: %v", r.LatestTag, e.Label(), err)
+		return
+	}
+	r.LatestPublished = published.UTC().Format(time.RFC3339)
+	r.LatestAgeDays = int(time.Since(published).Hours() / 24)
+}
+
+// bumpReported reports whether the bump from current to latest is at least
+// minBump. Calendar and lexical versions have no bump levels so every bump
+// counts.
+func (e SourceEntry) bumpReported(current, latest string, minBump int) bool {
+	bump := e.bumpLevel(current, latest)
+	return bump == BumpUnknown || bump <= minBump
+}
+
+// bumpLevel returns the part of the version bumped from current to latest as
+// semverBump does, or BumpUnknown for calendar and lexical versions and
+// versions semverBump cannot compare.
+func (e SourceEntry) bumpLevel(current, latest string) int {
+	if e.Versioning == versioningCalver || e.Versioning == versioningLexical {
+		return BumpUnknown
+	}
+	bump, err := semverBump(current, latest)
+	if err != nil {
+		return BumpUnknown
+	}
+	return bump
+}
+
+// checkBranch checks whether the head of e's branch is still the recorded
+// commit. Commits may be recorded abbreviated.
+func (c *Checker) checkBranch(ctx context.Context, e SourceEntry, p provider, r Result) (Result, error) {
+	r.CurrentTag, r.Branch = e.Commit, e.Branch
+	hc, ok := p.(headChecker)
+	if !ok {
+		return r, fmt.Errorf("branches of %s sources cannot be checked", e.Provider)
+	}
+	if c.Snapshot != nil {
+		head, ok := c.Snapshot.latest(e)
+		if !ok {
+			r.Status = StatusUnknown
+			r.Detail = fmt.Sprintf("no %q in the snapshot", SnapshotKey(e))
+			return r, nil
+		}
+		return branchStatus(e, r, head), nil
+	}
+	head, err := hc.head(ctx, c, e)
+	if rlErr, limited := err.(*rateLimitError); limited {
+		r.Status = StatusUnknown
+		r.Detail = rlErr.Error()
+		return r, nil
+	}
+	if nfErr, missing := err.(*notFoundError); missing {
+		r.Status = StatusNotFound
+		r.Detail, r.Hint = "branch", nfErr.hint()
+		return r, nil
+	}
+	if err != nil {
+		return r, fmt.Errorf("There was an error retrieving the head of branch %s of %s\n%v", e.Branch, e.Label(), err)
+	}
+	return branchStatus(e, r, head), nil
+}
+
+// branchStatus sets the status of r by whether head is e's commit.
+func branchStatus(e SourceEntry, r Result, head string) Result {
+	r.LatestTag = head
+	if strings.HasPrefix(head, strings.ToLower(e.Commit)) {
+		r.Status = StatusUpToDate
+	} else {
+		r.Status = StatusAdvanced
+	}
+	return r
+}
+
+// CheckNewer checks all entries of config selected by c.Filter concurrently,
+// at most c.Concurrency at once across all calls, an entry with several tags
+// once per tag. Results are returned in the order of config.Sources; entries
+// that failed are left out and their errors combined into the returned error.
+func (c *Checker) CheckNewer(ctx context.Context, config Config) ([]Result, error) {
+	type pin struct {
+		index int
+		e     SourceEntry
+	}
+	c.init()
+	pins := []pin{}
+	for i, e := range config.Sources {
+		for _, p := range e.pins() {
+			pins = append(pins, pin{i, p})
+		}
+	}
+	all := make([]Result, len(pins))
+	errs := make([]error, len(pins))
+	skipped := make([]bool, len(pins))
+	var wg sync.WaitGroup
+	for i, p := range pins {
+		if c.Filter != nil && !c.Filter(p.e) {
+			skipped[i] = true
+			continue
+		}
+		wg.Add(1)
+		go func(i int, e SourceEntry) {
+			defer wg.Done()
+			select {
+			case c.sem <- struct{}{}:
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			all[i], errs[i] = c.CheckEntry(ctx, e)
+			<-c.sem
+			if c.Checked != nil {
+				c.Checked()
+			}
+		}(i, p.e)
+	}
+	wg.Wait()
+
+	results := []Result{}
+	msgs := []string{}
+	checked := 0
+	for i, err := range errs {
+		if skipped[i] {
+			continue
+		}
+		checked++
+		if err != nil {
+			msgs = append(msgs, err.Error())
+			continue
+		}
+		all[i].Index = pins[i].index
+		all[i].Multi = len(config.Sources[pins[i].index].Tags) > 0
+		results = append(results, all[i])
+	}
+	if len(msgs) > 0 {
+		return results, fmt.Errorf("%d of %d sources could not be checked:\n%s", len(msgs), checked, strings.Join(msgs, "\n"))
+	}
+	return results, nil
+}
@@ -0,0 +1,89 @@
+package sourcerer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testChecker returns a Checker for tests against local servers: unpaced,
+// without retries or caching.
+func testChecker() *Checker {
+	c := NewChecker()
+	c.Rate, c.Retries = 1000, 0
+	return c
+}
+
+func TestCompareLatestUnparseable(t *testing.T) {
+	c := testChecker()
+	e := SourceEntry{Repo: "github.com/org/lib", Tag: "v1.0.0"}
+	for _, latest := range []string{"latest", "", "v"} {
+		r, err := c.compareLatest(context.Background(), e, execProvider{}, Result{LatestTag: latest})
+		if err != nil || r.Status != StatusUnknown || r.Detail != "unparseable upstream version "+latest {
+			t.Errorf("latest %q: got %s %q, %v; want unknown", latest, r.Status, r.Detail, err)
+		}
+	}
+}
+
+func TestCheckEntryNotFound(t *testing.T) {
+	s := httptest.NewServer(http.NotFoundHandler())
+	defer s.Close()
+	c := testChecker()
+	c.APIBase = s.URL
+	e := SourceEntry{Repo: strings.TrimPrefix(s.URL, "http://") + "/org/gone", Tag: "v1.0.0"}
+	r, err := c.CheckEntry(context.Background(), e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Status != StatusNotFound || r.Detail != "release" || r.Hint == "" {
+		t.Errorf("got %s %q with hint %q, want notfound", r.Status, r.Detail, r.Hint)
+	}
+}
+
+func TestCompareLatestFloor(t *testing.T) {
+	c := testChecker()
+	e := SourceEntry{Repo: "github.com/org/lib", Tag: ">=1.4.0"}
+	if !IsFloor(e.Tag) {
+		t.Fatalf("%s is not a floor", e.Tag)
+	}
+	for _, want := range []struct {
+		latest string
+		status Status
+	}{
+		{"v1.6.0", StatusUpToDate},
+		{"v1.4.0", StatusUpToDate},
+		{"v1.3.0", StatusOutdated},
+	} {
+		r, err := c.compareLatest(context.Background(), e, execProvider{}, Result{LatestTag: want.latest})
+		if err != nil || r.Status != want.status {
+			t.Errorf("latest %s: got %s, %v; want %s", want.latest, r.Status, err, want.status)
+		}
+	}
+}
+
+func TestCheckSnapshotComponents(t *testing.T) {
+	c := testChecker()
+	api := SourceEntry{Repo: "github.com/org/mono", Tag: "api/v1.0.0", TagComponent: "api"}
+	worker := SourceEntry{Repo: "github.com/org/mono", Tag: "worker/v1.0.0", TagComponent: "worker"}
+	if SnapshotKey(api) == SnapshotKey(worker) {
+		t.Fatalf("both components are keyed as %q", SnapshotKey(api))
+	}
+	c.Snapshot = &Snapshot{Sources: map[string]SnapshotSource{
+		SnapshotKey(api):    {Latest: "api/v2.0.0"},
+		SnapshotKey(worker): {Latest: "worker/v1.0.0"},
+	}}
+	for _, want := range []struct {
+		e      SourceEntry
+		status Status
+	}{
+		{api, StatusOutdated},
+		{worker, StatusUpToDate},
+	} {
+		r, err := c.CheckEntry(context.Background(), want.e)
+		if err != nil || r.Status != want.status {
+			t.Errorf("%s: got %s, %v; want %s", SnapshotKey(want.e), r.Status, err, want.status)
+		}
+	}
+}
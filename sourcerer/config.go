@@ -0,0 +1,271 @@
+package sourcerer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+var repoRE = regexp.MustCompile(`^(?:https?://)?([^/]+)/([^/]+)/([^/]+?)(?:\.git)?(?:/.*)?$`)
+
+// ParseConfig decodes and validates data, the manifest at filename. The format
+// is chosen by extension: .toml and .json manifests are decoded as such and
+// anything else, including the extensionless default, as YAML.
+func (c *Checker) ParseConfig(filename string, data []byte) (Config, error) {
+	var config Config
+	var err error
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".toml":
+		err = toml.Unmarshal(data, &config)
+		if err != nil {
+			return config, fmt.Errorf("Invalid toml\n%v", err)
+		}
+	case ".json":
+		err = json.Unmarshal(data, &config)
+		if err != nil {
+			return config, fmt.Errorf("Invalid json\n%v", err)
+		}
+	default:
+		err = yaml.Unmarshal(data, &config)
+		if err != nil {
+			return config, fmt.Errorf("Invalid yaml\n%v", err)
+		}
+	}
+	err = applyDefaults(&config)
+	if err != nil {
+		return config, fmt.Errorf("Invalid config\n%v", err)
+	}
+	if !c.NoEnv {
+		err = expandEnv(&config)
+		if err != nil {
+			return config, fmt.Errorf("Invalid config\n%v", err)
+		}
+	}
+	inferProviders(&config)
+	err = c.validateConfig(config)
+	if err != nil {
+		return config, fmt.Errorf("Invalid config\n%v", err)
+	}
+
+	return config, err
+}
+
+// applyDefaults fills in the settings of each source left empty from the
+// manifest's defaults. Booleans can only be defaulted to true, as a source
+// cannot tell an unset boolean from false. What identifies a source, its repo,
+// name, url, tag and version_path, cannot have a default.
+func applyDefaults(config *Config) error {
+	d := config.Defaults
+	if d.Repo != "" || d.Name != "" || d.URL != "" || d.Tag != "" || d.VersionPath != "" {
+		names := []string{}
+		for _, f := range defaultFields(&d) {
+			names = append(names, f.name)
+		}
+		return fmt.Errorf("defaults: only %s and %s can have defaults", strings.Join(names[:len(names)-1], ", "), names[len(names)-1])
+	}
+	defs := defaultFields(&d)
+	for i := range config.Sources {
+		e := &config.Sources[i]
+		for j, f := range defaultFields(e) {
+			if f.str != nil && *f.str == "" {
+				*f.str = *defs[j].str
+			}
+			if f.flag != nil {
+				*f.flag = *f.flag || *defs[j].flag
+			}
+		}
+	}
+	return nil
+}
+
+// defaultField is a setting of a source that can have a default, by its name
+// in the manifest. Either str or flag is set.
+type defaultField struct {
+	name string
+	str  *string
+	flag *bool
+}
+
+// defaultFields returns the settings of e that can have defaults.
+func defaultFields(e *SourceEntry) []defaultField {
+	return []defaultField{
+		{"provider", &e.Provider, nil},
+		{"api_base", &e.APIBase, nil},
+		{"source", &e.Source, nil},
+		{"tag_prefix", &e.TagPrefix, nil},
+		{"tag_pattern", &e.TagPattern, nil},
+		{"tag_component", &e.TagComponent, nil},
+		{"versioning", &e.Versioning, nil},
+		{"command", &e.Command, nil},
+		{"track", &e.Track, nil},
+		{"stable_only", nil, &e.StableOnly},
+		{"include_prerelease", nil, &e.IncludePrerelease},
+		{"pinned", nil, &e.Pinned},
+	}
+}
+
+// expandEnv replaces $VAR and ${VAR} references in the string fields of each
+// entry with the variable's value. Referencing an unset variable is an error.
+func expandEnv(config *Config) error {
+	for i := range config.Sources {
+		e := &config.Sources[i]
+		fields := []*string{&e.Repo, &e.Tag, &e.URL, &e.APIBase}
+		for j := range e.Tags {
+			fields = append(fields, &e.Tags[j])
+		}
+		for _, field := range fields {
+			var missing []string
+			*field = os.Expand(*field, func(name string) string {
+				v, ok := os.LookupEnv(name)
+				if !ok {
+					missing = append(missing, name)
+				}
+				return v
+			})
+			if len(missing) > 0 {
+				return fmt.Errorf("source %d: undefined environment variable %s", i, strings.Join(missing, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// inferProviders sets the provider of each source with a repo that does not
+// set one, from the repo's host. Sources on other hosts are left to the
+// default GitHub provider, for GitHub Enterprise instances.
+func inferProviders(config *Config) {
+	for i := range config.Sources {
+		e := &config.Sources[i]
+		if e.Provider == "" && e.Repo != "" {
+			e.Provider = hostProviders[repoHost(e.Repo)]
+		}
+	}
+}
+
+func (c *Checker) validateConfig(config Config) error {
+	for i, entry := range config.Sources {
+		if len(entry.Tags) != 0 {
+			if len(entry.Tag) != 0 || len(entry.Repo) == 0 || len(entry.Branch) != 0 {
+				return fmt.Errorf("source %d: tags require a repo, and no tag or branch", i)
+			}
+		}
+		for _, e := range entry.pins() {
+			if err := c.validateEntry(i, e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateEntry validates e, the source at index i or one of its pins.
+func (c *Checker) validateEntry(i int, e SourceEntry) error {
+	if _, ok := providers[e.Provider]; e.Provider != "" && !ok {
+		return fmt.Errorf("source %d: unknown provider %q; expected one of %s", i, e.Provider, strings.Join(providerNames(), ", "))
+	}
+	if len(e.URL) != 0 && len(e.Repo) != 0 {
+		return fmt.Errorf("source %d: cannot define a url and a repo; pick one", i)
+	}
+	if host := repoHost(e.Repo); e.Provider == "" && host != "" && host != githubHost(c.githubAPIBase(e)) {
+		return fmt.Errorf("source %d: cannot infer the provider of %s from its host %q; set a provider, or an api_base for GitHub Enterprise", i, e.Repo, host)
+	}
+	if isRegistryProvider(e.Provider) {
+		if len(e.Name) == 0 || len(e.Repo) != 0 || len(e.URL) != 0 {
+			return fmt.Errorf("source %d: %s sources must define a name and no repo or url", i, e.Provider)
+		}
+		if len(e.Tag) == 0 {
+			return fmt.Errorf("source %d: when defining a name you must also define a tag", i)
+		}
+	} else if e.Provider == ProviderExec {
+		if len(e.Command) == 0 || len(e.Tag) == 0 || len(e.URL) != 0 {
+			return fmt.Errorf("source %d: exec sources must define a command and a tag, and no url", i)
+		}
+	} else if len(e.URL) == 0 && len(e.Repo) == 0 {
+		return fmt.Errorf("source %d: must define either a url or a repo", i)
+	}
+	if len(e.Command) != 0 && e.Provider != ProviderExec {
+		return fmt.Errorf("source %d: a command can only be used with provider exec", i)
+	}
+	if len(e.Branch) != 0 || len(e.Commit) != 0 {
+		if len(e.Branch) == 0 || !commitRE.MatchString(e.Commit) || len(e.Repo) == 0 || len(e.Tag) != 0 {
+			return fmt.Errorf("source %d: a branch requires a repo and a commit of at least 7 hex digits, and no tag", i)
+		}
+		if e.Provider != "" && e.Provider != ProviderGitHub {
+			return fmt.Errorf("source %d: branches can only be checked for github repos", i)
+		}
+	} else if len(e.LatestAsset) != 0 {
+		if len(e.Repo) == 0 || (e.Provider != "" && e.Provider != ProviderGitHub) || len(e.Tag) != 0 || len(e.Asset) != 0 {
+			return fmt.Errorf("source %d: a latest_asset requires a github repo, and no tag or asset", i)
+		}
+	} else if len(e.Repo) != 0 && len(e.Tag) == 0 {
+		return fmt.Errorf("source %d: when defining a repo you must also define a tag to pull", i)
+	}
+	if len(e.URL) != 0 && len(e.Tag) != 0 && len(e.VersionPath) == 0 {
+		return fmt.Errorf("source %d: a tag can only be used with a url when a version_path is set", i)
+	}
+	if len(e.VersionPath) != 0 {
+		if len(e.URL) == 0 || len(e.Tag) == 0 {
+			return fmt.Errorf("source %d: a version_path requires a url and a tag", i)
+		}
+		if _, err := parseJSONPath(e.VersionPath); err != nil {
+			return fmt.Errorf("source %d: %v", i, err)
+		}
+	}
+	if err := validateTagScheme(e); err != nil {
+		return fmt.Errorf("source %d: %v", i, err)
+	}
+	if IsConstraint(e.Tag) {
+		if _, err := parseConstraint(e.Tag); err != nil {
+			return fmt.Errorf("source %d: %v", i, err)
+		}
+	} else if c.Strict && len(e.Tag) != 0 {
+		v, ok := e.Version(e.Tag)
+		if !ok {
+			v = e.Tag
+		}
+		if err := e.parseVersion(v); err != nil {
+			return fmt.Errorf("source %d: tag %q is not a version\n%v", i, e.Tag, err)
+		}
+	}
+	if e.Source != "" && e.Source != sourceReleases && e.Source != sourceTags {
+		return fmt.Errorf("source %d: unknown source %q; expected %s or %s", i, e.Source, sourceReleases, sourceTags)
+	}
+	if e.Versioning != "" && e.Versioning != versioningSemver && e.Versioning != versioningCalver && e.Versioning != versioningLexical {
+		return fmt.Errorf("source %d: unknown versioning %q; expected %s, %s or %s", i, e.Versioning, versioningSemver, versioningCalver, versioningLexical)
+	}
+	if e.Versioning == versioningLexical && (IsConstraint(e.Tag) || e.Track != "") {
+		return fmt.Errorf("source %d: lexical versions cannot be used with a constraint or track", i)
+	}
+	if len(e.EOLProduct) != 0 && (len(e.Tag) == 0 || IsConstraint(e.Tag)) {
+		return fmt.Errorf("source %d: an eol_product requires a tag, and no constraint", i)
+	}
+	if len(e.Asset) != 0 || len(e.SHA256) != 0 {
+		if len(e.Asset) == 0 || !sha256RE.MatchString(e.SHA256) {
+			return fmt.Errorf("source %d: an asset requires a sha256 of 64 hex digits, and a sha256 requires an asset", i)
+		}
+		if len(e.Repo) == 0 || (e.Provider != "" && e.Provider != ProviderGitHub) || len(e.Tag) == 0 || IsConstraint(e.Tag) {
+			return fmt.Errorf("source %d: assets can only be verified for github repos pinned to a tag", i)
+		}
+	}
+	return nil
+}
+
+var commitRE = regexp.MustCompile("^[0-9a-fA-F]{7,40}$")
+
+// parseRepo splits a repo of the form host/owner/name, where host must match
+// the given host, into its owner and name. The repo may also be written as an
+// https URL, with a .git suffix or followed by a path within the repo, such as
+// github.com/owner/name/tree/main.
+func parseRepo(repo, host string) (string, string, error) {
+	match := repoRE.FindStringSubmatch(repo)
+	if len(match) != 4 || match[1] != host {
+		return "", "", fmt.Errorf("Could not parse: %s as a %s repo, found: %v", repo, host, match)
+	}
+	return match[2], match[3], nil
+}
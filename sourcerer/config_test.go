@@ -0,0 +1,139 @@
+package sourcerer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseConfigYAMLAnchors(t *testing.T) {
+	manifest := `
+x-common: &common
+  tag_prefix: release-
+  stable_only: true
+defaults:
+  tag_prefix: v
+  versioning: semver
+sources:
+  - repo: github.com/org/a
+    tag: &tag release-1.2.0
+    <<: *common
+  - repo: github.com/org/b
+    tag: *tag
+    <<: *common
+  - repo: github.com/org/c
+    tag: v2.0.0
+`
+	config, err := NewChecker().ParseConfig("SOURCES", []byte(manifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Sources) != 3 {
+		t.Fatalf("got %d sources, want 3", len(config.Sources))
+	}
+	for _, e := range config.Sources[:2] {
+		if e.Tag != "release-1.2.0" || e.TagPrefix != "release-" || !e.StableOnly || e.Versioning != "semver" {
+			t.Errorf("%s: got tag %q, tag_prefix %q, stable_only %v, versioning %q; want the anchored tag, the merged settings and the defaulted versioning",
+				e.Repo, e.Tag, e.TagPrefix, e.StableOnly, e.Versioning)
+		}
+	}
+	if e := config.Sources[2]; e.TagPrefix != "v" || e.StableOnly {
+		t.Errorf("%s: got tag_prefix %q, stable_only %v; want only the defaults", e.Repo, e.TagPrefix, e.StableOnly)
+	}
+}
+
+func TestParseRepo(t *testing.T) {
+	for _, repo := range []string{
+		"github.com/org/lib",
+		"github.com/org/lib.git",
+		"github.com/org/lib/tree/main",
+		"https://github.com/org/lib",
+		"https://github.com/org/lib.git",
+		"http://github.com/org/lib/tree/main/docs",
+	} {
+		owner, name, err := parseRepo(repo, "github.com")
+		if err != nil || owner != "org" || name != "lib" {
+			t.Errorf("parseRepo(%s) = %q, %q, %v; want org, lib", repo, owner, name, err)
+		}
+	}
+	for _, repo := range []string{"gitlab.com/org/lib", "github.com/org", "https://github.com/"} {
+		if owner, name, err := parseRepo(repo, "github.com"); err == nil {
+			t.Errorf("parseRepo(%s) = %q, %q; want an error", repo, owner, name)
+		}
+	}
+}
+
+func TestParseConfigDefaultsNaming(t *testing.T) {
+	manifest := "defaults:\n  repo: github.com/org/a\nsources:\n  - repo: github.com/org/b\n    tag: v1.0.0\n"
+	_, err := NewChecker().ParseConfig("SOURCES", []byte(manifest))
+	if err == nil {
+		t.Fatal("got no error for a defaulted repo")
+	}
+	for _, f := range defaultFields(&SourceEntry{}) {
+		if !strings.Contains(err.Error(), f.name) {
+			t.Errorf("error %q does not name %s", err, f.name)
+		}
+	}
+}
+
+func TestParseConfigEntriesOverrideDefaults(t *testing.T) {
+	manifest := `
+defaults:
+  provider: gitlab
+  tag_prefix: v
+  versioning: calver
+  track: major
+  stable_only: true
+sources:
+  - repo: gitlab.com/org/a
+    tag: release-1.2.0
+    tag_prefix: release-
+    versioning: semver
+    track: 1.x
+  - repo: gitlab.com/org/b
+    tag: v2024.01
+`
+	config, err := NewChecker().ParseConfig("SOURCES", []byte(manifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := config.Sources[0]; e.TagPrefix != "release-" || e.Versioning != "semver" || e.Track != "1.x" || e.Provider != "gitlab" || !e.StableOnly {
+		t.Errorf("%s: got tag_prefix %q, versioning %q, track %q, provider %q, stable_only %v; want its own settings over the defaults",
+			e.Repo, e.TagPrefix, e.Versioning, e.Track, e.Provider, e.StableOnly)
+	}
+	if e := config.Sources[1]; e.TagPrefix != "v" || e.Versioning != "calver" || e.Track != "major" {
+		t.Errorf("%s: got tag_prefix %q, versioning %q, track %q; want the defaults", e.Repo, e.TagPrefix, e.Versioning, e.Track)
+	}
+}
+
+func TestParseConfigInfersProviders(t *testing.T) {
+	for _, c := range []struct {
+		repo     string
+		provider string
+	}{
+		{"github.com/org/lib", ProviderGitHub},
+		{"https://gitlab.com/org/lib", ProviderGitLab},
+		{"bitbucket.org/org/lib", ProviderBitbucket},
+	} {
+		manifest := fmt.Sprintf("sources:\n  - repo: %s\n    tag: v1.0.0\n", c.repo)
+		config, err := NewChecker().ParseConfig("SOURCES", []byte(manifest))
+		if err != nil {
+			t.Errorf("%s: %v", c.repo, err)
+			continue
+		}
+		if got := config.Sources[0].Provider; got != c.provider {
+			t.Errorf("%s: got provider %q, want %q", c.repo, got, c.provider)
+		}
+	}
+
+	manifest := "sources:\n  - repo: git.example.com/org/lib\n    tag: v1.0.0\n"
+	_, err := NewChecker().ParseConfig("SOURCES", []byte(manifest))
+	if err == nil || !strings.Contains(err.Error(), `cannot infer the provider of git.example.com/org/lib from its host "git.example.com"`) {
+		t.Errorf("got %v for an unknown host, want the provider to be asked for", err)
+	}
+	for _, set := range []string{"provider: gitlab", "api_base: https://git.example.com/api/v3"} {
+		if _, err := NewChecker().ParseConfig("SOURCES", []byte(manifest+"    "+set+"\n")); err != nil {
+			t.Errorf("unknown host with %s: %v", set, err)
+		}
+	}
+}
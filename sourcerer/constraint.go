@@ -0,0 +1,78 @@
+package sourcerer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// constraintRE matches a single bound of a constraint such as >=1.4.
+var constraintRE = regexp.MustCompile(`^\s*(>=|<=|!=|>|<|=)\s*(\S+)\s*$`)
+
+// bound is one comparison of a constraint, e.g. op ">=" and version "1.4".
+type bound struct {
+	op      string
+	version string
+}
+
+// constraint is a comma separated list of bounds that must all hold, such as
+// ">=1.4, <2.0".
+type constraint []bound
+
+// IsConstraint reports whether tag is written as a constraint rather than a
+// pinned version.
+func IsConstraint(tag string) bool {
+	t := strings.TrimSpace(tag)
+	return t != "" && strings.ContainsRune("<>=!", rune(t[0]))
+}
+
+// IsFloor reports whether tag is a constraint that only sets a minimum
+// version, such as >=1.4.0, so that any newer upstream version satisfies it.
+func IsFloor(tag string) bool {
+	c, err := parseConstraint(tag)
+	return IsConstraint(tag) && err == nil && len(c) == 1 && (c[0].op == ">=" || c[0].op == ">")
+}
+
+func parseConstraint(s string) (constraint, error) {
+	c := constraint{}
+	for _, part := range strings.Split(s, ",") {
+		m := constraintRE.FindStringSubmatch(part)
+		if m == nil {
+			return nil, fmt.Errorf("invalid constraint %q", s)
+		}
+		if !semverRE.MatchString(m[2]) {
+			return nil, fmt.Errorf("invalid constraint %q: %s is not a version", s, m[2])
+		}
+		c = append(c, bound{op: m[1], version: m[2]})
+	}
+	return c, nil
+}
+
+// allows reports whether version satisfies every bound of c.
+func (c constraint) allows(version string) (bool, error) {
+	for _, b := range c {
+		rel, err := CompareSemver(version, b.version)
+		if err != nil {
+			return false, err
+		}
+		var ok bool
+		switch b.op {
+		case ">=":
+			ok = rel >= 0
+		case "<=":
+			ok = rel <= 0
+		case ">":
+			ok = rel > 0
+		case "<":
+			ok = rel < 0
+		case "=":
+			ok = rel == 0
+		case "!=":
+			ok = rel != 0
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
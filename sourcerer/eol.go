@@ -0,0 +1,88 @@
+package sourcerer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// eolCycle is a release cycle of an endoflife.date product. EOL is the date
+// the cycle reaches its end of life, or a boolean for products without one.
+type eolCycle struct {
+	Cycle string          `json:"cycle"`
+	EOL   json.RawMessage `json:"eol"`
+}
+
+// checkEOL looks up the release cycle of e's tag in the endoflife.date cycles
+// of e.EOLProduct, marking r as end-of-life if the cycle is past its EOL date.
+// A tag matching no cycle is only logged.
+func (c *Checker) checkEOL(ctx context.Context, e SourceEntry, r *Result) error {
+	productURL := strings.TrimSuffix(c.EOLAPI, "/") + "/" + url.PathEscape(e.EOLProduct) + ".json"
+	req, err := http.NewRequestWithContext(ctx, "GET", productURL, nil)
+	if err != nil {
+		return err
+	}
+	body, err := c.fetch(req)
+	if _, missing := err.(*notFoundError); missing {
+		return fmt.Errorf("endoflife.date has no product %s", e.EOLProduct)
+	}
+	if err != nil {
+		return fmt.Errorf("There was an error retrieving the release cycles of %s\n%v", e.EOLProduct, err)
+	}
+	var cycles []eolCycle
+	if err := json.Unmarshal(body, &cycles); err != nil {
+		return fmt.Errorf("unable to parse body of url %s\n%v\n body:\n%s", productURL, err, string(body))
+	}
+
+	cycle, ok := matchCycle(e.versionOf(), cycles)
+	if !ok {
+		c.warnf("%s: no %s release cycle matches %s", e.Label(), e.EOLProduct, e.Tag)
+		return nil
+	}
+	c.debugf("%s %s is in %s cycle %s, eol %s", e.Label(), e.Tag, e.EOLProduct, cycle.Cycle, cycle.EOL)
+	var eol bool
+	var date string
+	if err := json.Unmarshal(cycle.EOL, &date); err == nil {
+		t, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return fmt.Errorf("invalid eol date %q of %s cycle %s", date, e.EOLProduct, cycle.Cycle)
+		}
+		eol = !time.Now().Before(t)
+	} else if err := json.Unmarshal(cycle.EOL, &eol); err != nil {
+		return fmt.Errorf("invalid eol %s of %s cycle %s", cycle.EOL, e.EOLProduct, cycle.Cycle)
+	}
+	if eol {
+		r.Status = StatusEOL
+		r.EOLCycle, r.EOLDate = cycle.Cycle, date
+	}
+	return nil
+}
+
+// matchCycle returns the cycle version v belongs to: the most specific one,
+// such as 1.21 over 1, whose parts are the leading parts of v.
+func matchCycle(v string, cycles []eolCycle) (eolCycle, bool) {
+	sv, err := mkSemver(v)
+	if err != nil {
+		return eolCycle{}, false
+	}
+	var best eolCycle
+	bestLen := 0
+	for _, c := range cycles {
+		cv, err := mkSemver(c.Cycle)
+		if err != nil || len(cv.parts) > len(sv.parts) || len(cv.parts) <= bestLen {
+			continue
+		}
+		matches := true
+		for i, p := range cv.parts {
+			matches = matches && sv.parts[i] == p
+		}
+		if matches {
+			best, bestLen = c, len(cv.parts)
+		}
+	}
+	return best, bestLen > 0
+}
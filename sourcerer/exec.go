@@ -0,0 +1,64 @@
+package sourcerer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// execProvider runs a user supplied command to find the latest version, for
+// registries sourcerer has no provider for. The command is run by sh with the
+// source's repo (or name) and tag as $1 and $2, and as $SOURCERER_REPO,
+// $SOURCERER_NAME and $SOURCERER_TAG. The first non-empty line it prints is
+// the latest version.
+type execProvider struct{}
+
+func (execProvider) lookupURL(c *Checker, e SourceEntry) (string, error) {
+	// Not a URL, but it identifies the lookup in -dry-run output and for
+	// sharing results between entries.
+	return fmt.Sprintf("exec: %s [%s %s]", e.Command, execSubject(e), e.Tag), nil
+}
+
+func (execProvider) latest(ctx context.Context, c *Checker, e SourceEntry, _ string) (string, bool, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", e.Command, "sourcerer", execSubject(e), e.Tag)
+	cmd.Env = append(os.Environ(),
+		"SOURCERER_REPO="+e.Repo,
+		"SOURCERER_NAME="+e.Name,
+		"SOURCERER_TAG="+e.Tag,
+	)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", false, fmt.Errorf("command %q timed out after %v\n%s", e.Command, c.Timeout, strings.TrimSpace(stderr.String()))
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("command %q failed: %v\n%s", e.Command, err, strings.TrimSpace(stderr.String()))
+	}
+	if stderr.Len() > 0 {
+		c.debugf("%s: %s", e.Command, strings.TrimSpace(stderr.String()))
+	}
+	for _, l := range strings.Split(stdout.String(), "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			return l, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// execSubject returns what an exec source is about: its repo, or else its
+// name.
+func execSubject(e SourceEntry) string {
+	if e.Repo != "" {
+		return e.Repo
+	}
+	return e.Name
+}
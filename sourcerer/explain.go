@@ -0,0 +1,62 @@
+package sourcerer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// explanation describes how r came to be classified by compareLatest: the
+// latest tag found, the versions of both tags as e's versioning scheme parses
+// them and the comparison, for debugging version parsing.
+func explanation(e SourceEntry, r Result, err error) string {
+	lines := []string{fmt.Sprintf("EXPLAIN %s: latest %s %s", e.Label(), e.sourceKind(), r.LatestTag)}
+	current := e.versionOf()
+	latest := r.LatestTag
+	if v, ok := e.Version(r.LatestTag); ok {
+		latest = v
+	}
+	if IsConstraint(e.Tag) {
+		lines = append(lines, fmt.Sprintf("\tconstraint %s", e.Tag))
+	} else {
+		lines = append(lines, fmt.Sprintf("\tcurrent %s: %s", e.Tag, parsedVersion(e, current)))
+	}
+	lines = append(lines, fmt.Sprintf("\tlatest %s: %s", r.LatestTag, parsedVersion(e, latest)))
+	if !IsConstraint(e.Tag) {
+		if rel, cerr := e.compareVersions(current, latest); cerr == nil {
+			lines = append(lines, fmt.Sprintf("\tcompare(%s, %s) = %d", current, latest, rel))
+		}
+	}
+	if err != nil {
+		lines = append(lines, fmt.Sprintf("\terror: %v", err))
+	} else {
+		status := string(r.Status)
+		if r.Detail != "" {
+			status += " (" + r.Detail + ")"
+		}
+		lines = append(lines, "\tstatus "+status)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parsedVersion describes v as e's versioning scheme parses it.
+func parsedVersion(e SourceEntry, v string) string {
+	switch e.Versioning {
+	case versioningLexical:
+		return fmt.Sprintf("lexical %q", v)
+	case versioningCalver:
+		parts, err := mkCalver(v)
+		if err != nil {
+			return err.Error()
+		}
+		return fmt.Sprintf("calver %v", parts)
+	}
+	sv, err := mkSemver(v)
+	if err != nil {
+		return err.Error()
+	}
+	s := fmt.Sprintf("semver %v", sv.parts)
+	if len(sv.prerelease) > 0 {
+		s += " prerelease " + strings.Join(sv.prerelease, ".")
+	}
+	return s
+}
@@ -0,0 +1,486 @@
+package sourcerer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultAPIBase = "https://api.github.com"
+
+// githubAPIBase returns the API base URL to use for e, preferring the entry's
+// own setting, then c.APIBase and finally $GITHUB_API_URL. A missing
+// scheme defaults to https.
+func (c *Checker) githubAPIBase(e SourceEntry) string {
+	base := e.APIBase
+	if base == "" {
+		base = c.APIBase
+	}
+	if base == "" {
+		base = os.Getenv("GITHUB_API_URL")
+	}
+	if base == "" {
+		base = defaultAPIBase
+	}
+	if !strings.Contains(base, "://") {
+		base = "https://" + base
+	}
+	return strings.TrimSuffix(base, "/")
+}
+
+// githubHost returns the host repos are expected to be prefixed with for the
+// API at base: github.com for the public API and the API's own host for
+// GitHub Enterprise.
+func githubHost(base string) string {
+	u, err := url.Parse(base)
+	if err != nil || u.Host == "api.github.com" {
+		return "github.com"
+	}
+	return u.Host
+}
+
+// githubToken returns the token to authenticate requests to host with,
+// preferring c.Token, then the environment and finally the password of host
+// in netrc. The API host api.github.com also matches a github.com machine. It
+// is empty when requests should be unauthenticated.
+func (c *Checker) githubToken(host string) string {
+	if c.Token != "" {
+		return c.Token
+	}
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		return token
+	}
+	hosts := []string{host}
+	if host == "api.github.com" {
+		hosts = append(hosts, "github.com")
+	}
+	m, _ := netrcLookup(hosts...)
+	return m.password
+}
+
+// setGitHubAuth authenticates req with the token for its host, if any.
+func (c *Checker) setGitHubAuth(req *http.Request) {
+	if token := c.githubToken(req.URL.Hostname()); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// githubGet requests url, sending etag as If-None-Match when it is not empty.
+func (c *Checker) githubGet(ctx context.Context, url, etag string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setGitHubAuth(req)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	return c.doWithRetry(req)
+}
+
+// githubFetch returns the body of the GitHub API resource at url. A
+// *rateLimitError is returned when GitHub rejects the request because the
+// rate limit is exhausted, a *notFoundError when the resource does not exist.
+// Responses are cached: within c.CacheTTL they are used as is, after that they
+// are revalidated with their ETag so an unchanged resource is still served
+// from the cache.
+func (c *Checker) githubFetch(ctx context.Context, url string) ([]byte, error) {
+	body, _, err := c.githubFetchPage(ctx, url)
+	return body, err
+}
+
+// githubFetchPages returns the bodies of the pages of the GitHub API list at
+// url, following Link headers for at most c.MaxPages pages.
+func (c *Checker) githubFetchPages(ctx context.Context, url string) ([][]byte, error) {
+	pages := [][]byte{}
+	for url != "" && len(pages) < c.MaxPages {
+		body, next, err := c.githubFetchPage(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, body)
+		url = next
+	}
+	if url != "" {
+		c.debugf("not following more than %d pages, stopped before %s", c.MaxPages, url)
+	}
+	return pages, nil
+}
+
+// githubFetchPage is githubFetch, also returning the URL of the next page of
+// results from the response's Link header, if any.
+func (c *Checker) githubFetchPage(ctx context.Context, url string) (body []byte, next string, err error) {
+	cached := c.loadCache(url)
+	etag := ""
+	if cached != nil {
+		if cached.fresh(c.CacheTTL) {
+			c.debugf("using fresh cached response for %s", url)
+			return cached.Body, cached.Next, nil
+		}
+		etag = cached.ETag
+	}
+	res, err := c.githubGet(ctx, url, etag)
+	if err != nil {
+		return nil, "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotModified && cached != nil {
+		c.debugf("using revalidated cached response for %s", url)
+		cached.Fetched = time.Now()
+		c.storeCache(url, *cached)
+		return cached.Body, cached.Next, nil
+	}
+	if err := checkRateLimit(res); err != nil {
+		return nil, "", err
+	}
+	if res.StatusCode == http.StatusNotFound {
+		return nil, "", &notFoundError{url: url, authenticated: authenticated(res.Request)}
+	}
+	body, err = ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to read body of url %s\n%v", url, err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpected status %s from %s\n body:\n%s", res.Status, url, string(body))
+	}
+	next = nextLink(res.Header.Get("Link"))
+	c.storeCache(url, cacheEntry{ETag: res.Header.Get("ETag"), Next: next, Body: body, Fetched: time.Now()})
+	return body, next, nil
+}
+
+// nextLink returns the URL with rel="next" in a Link header such as
+// <https://api.github.com/...&page=2>; rel="next", <...>; rel="last".
+func nextLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		parts := strings.Split(link, ";")
+		target := strings.TrimSpace(parts[0])
+		if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+		for _, p := range parts[1:] {
+			if strings.TrimSpace(p) == `rel="next"` {
+				return target[1 : len(target)-1]
+			}
+		}
+	}
+	return ""
+}
+
+// rateLimitError describes a request rejected by GitHub's rate limit.
+type rateLimitError struct {
+	reset         string
+	limit         string
+	authenticated bool
+}
+
+func (e *rateLimitError) Error() string {
+	if e.authenticated {
+		return fmt.Sprintf("Rate limited, resets at %s (authenticated limit: %s requests/hour)", e.reset, e.limit)
+	}
+	return fmt.Sprintf("Rate limited, resets at %s (set GITHUB_TOKEN for a higher limit)", e.reset)
+}
+
+// checkRateLimit returns a *rateLimitError if res was rejected by GitHub's
+// rate limit.
+func checkRateLimit(res *http.Response) error {
+	if res.StatusCode != http.StatusForbidden || res.Header.Get("X-RateLimit-Remaining") != "0" {
+		return nil
+	}
+	reset := "an unknown time"
+	if secs, err := strconv.ParseInt(res.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		reset = time.Unix(secs, 0).Format(time.RFC1123)
+	}
+	return &rateLimitError{reset: reset, limit: res.Header.Get("X-RateLimit-Limit"), authenticated: res.Request != nil && authenticated(res.Request)}
+}
+
+// RateLimit is the request budget of a GitHub API.
+type RateLimit struct {
+	Limit, Remaining int
+	Reset            time.Time
+}
+
+// RateLimit queries the rate limit of the GitHub API of sources that set no
+// api_base.
+func (c *Checker) RateLimit(ctx context.Context) (RateLimit, error) {
+	url := c.githubAPIBase(SourceEntry{}) + "/rate_limit"
+	res, err := c.githubGet(ctx, url, "")
+	if err != nil {
+		return RateLimit{}, err
+	}
+	defer res.Body.Close()
+	var status struct {
+		Resources struct {
+			Core struct {
+				Limit     int   `json:"limit"`
+				Remaining int   `json:"remaining"`
+				Reset     int64 `json:"reset"`
+			} `json:"core"`
+		} `json:"resources"`
+	}
+	if res.StatusCode != http.StatusOK {
+		return RateLimit{}, fmt.Errorf("unexpected status %s from %s", res.Status, url)
+	}
+	if err := json.NewDecoder(res.Body).Decode(&status); err != nil {
+		return RateLimit{}, fmt.Errorf("unable to parse body of url %s\n%v", url, err)
+	}
+	core := status.Resources.Core
+	return RateLimit{Limit: core.Limit, Remaining: core.Remaining, Reset: time.Unix(core.Reset, 0)}, nil
+}
+
+// githubProvider looks up releases and tags with the GitHub API.
+type githubProvider struct{}
+
+// githubRepoURL returns the API URL of e's repo.
+func (c *Checker) githubRepoURL(e SourceEntry) (string, error) {
+	base := c.githubAPIBase(e)
+	owner, repo, err := parseRepo(e.Repo, githubHost(base))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/repos/%s/%s", base, owner, repo), nil
+}
+
+func (githubProvider) lookupURL(c *Checker, e SourceEntry) (string, error) {
+	repoURL, err := c.githubRepoURL(e)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case e.Branch != "":
+		return repoURL + "/commits/" + e.Branch, nil
+	case e.Source == sourceTags:
+		return repoURL + "/tags?per_page=100", nil
+	case listsReleases(e):
+		return repoURL + "/releases?per_page=100", nil
+	}
+	return repoURL + "/releases/latest", nil
+}
+
+func (githubProvider) tagExists(ctx context.Context, c *Checker, e SourceEntry, tag string) (bool, error) {
+	repoURL, err := c.githubRepoURL(e)
+	if err != nil {
+		return false, err
+	}
+	ref := repoURL + "/releases/tags/" + url.PathEscape(tag)
+	if e.Source == sourceTags {
+		ref = repoURL + "/git/ref/tags/" + url.PathEscape(tag)
+	}
+	res, err := c.githubGet(ctx, ref, "")
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	if err := checkRateLimit(res); err != nil {
+		return false, err
+	}
+	switch res.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+	return false, fmt.Errorf("unable to look up %s: %s", ref, res.Status)
+}
+
+func (githubProvider) latest(ctx context.Context, c *Checker, e SourceEntry, url string) (tag string, ok bool, err error) {
+	switch {
+	case e.Source == sourceTags:
+		names, err := c.tagNames(ctx, url)
+		if err != nil {
+			return "", false, err
+		}
+		tag, ok = highestVersion(e, names)
+		return tag, ok, nil
+	case listsReleases(e):
+		names, err := c.releaseTags(ctx, url, e.IncludePrerelease)
+		if err != nil {
+			return "", false, err
+		}
+		tag, ok = highestVersion(e, names)
+		return tag, ok, nil
+	}
+	tag, ok, err = c.latestRelease(ctx, url)
+	if ok && !e.candidate(tag) {
+		ok = false
+	}
+	return tag, ok, err
+}
+
+// latestRelease returns the tag of the latest release at url, the
+// releases/latest resource of a repo. ok is false when the repo has no
+// latest release with a tag.
+func (c *Checker) latestRelease(ctx context.Context, url string) (tag string, ok bool, err error) {
+	body, err := c.githubFetch(ctx, url)
+	if err != nil {
+		return "", false, err
+	}
+	tag, ok, err = parseLatestTag(body)
+	if err != nil {
+		return "", false, fmt.Errorf("unable to parse body of url %s\n%v", url, err)
+	}
+	return tag, ok, nil
+}
+
+// parseLatestTag extracts the tag from a GitHub release body: its tag_name,
+// or else its name, which many releases leave empty or set to a title. ok is
+// false when the release defines neither.
+func parseLatestTag(body []byte) (tag string, ok bool, err error) {
+	var release struct {
+		TagName string `json:"tag_name"`
+		Name    string `json:"name"`
+	}
+	err = json.Unmarshal(body, &release)
+	if err != nil {
+		return "", false, fmt.Errorf("%v\n body:\n%s", err, string(body))
+	}
+	if release.TagName != "" {
+		return release.TagName, true, nil
+	}
+	return release.Name, release.Name != "", nil
+}
+
+// listsReleases reports whether e is compared against the releases list
+// instead of the latest release: to include prereleases, which the latest
+// release never is, or because the latest release may belong to another tag
+// scheme.
+func listsReleases(e SourceEntry) bool {
+	return e.IncludePrerelease || e.TagPrefix != "" || e.TagPattern != "" || e.TagComponent != "" || e.Track != ""
+}
+
+func (p githubProvider) head(ctx context.Context, c *Checker, e SourceEntry) (string, error) {
+	url, err := p.lookupURL(c, e)
+	if err != nil {
+		return "", err
+	}
+	body, err := c.githubFetch(ctx, url)
+	if err != nil {
+		return "", err
+	}
+	var commit struct {
+		SHA string `json:"sha"`
+	}
+	err = json.Unmarshal(body, &commit)
+	if err != nil || commit.SHA == "" {
+		return "", fmt.Errorf("unable to parse body of url %s\n%v\n body:\n%s", url, err, string(body))
+	}
+	return commit.SHA, nil
+}
+
+func (githubProvider) published(ctx context.Context, c *Checker, e SourceEntry, tag string) (time.Time, error) {
+	repoURL, err := c.githubRepoURL(e)
+	if err != nil {
+		return time.Time{}, err
+	}
+	ref := repoURL + "/releases/tags/" + url.PathEscape(tag)
+	if e.Source == sourceTags {
+		ref = repoURL + "/commits/" + url.PathEscape(tag)
+	}
+	body, err := c.githubFetch(ctx, ref)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var v struct {
+		PublishedAt time.Time `json:"published_at"`
+		Commit      struct {
+			Committer struct {
+				Date time.Time `json:"date"`
+			} `json:"committer"`
+		} `json:"commit"`
+	}
+	err = json.Unmarshal(body, &v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to parse body of url %s\n%v\n body:\n%s", ref, err, string(body))
+	}
+	if e.Source == sourceTags {
+		return v.Commit.Committer.Date, nil
+	}
+	return v.PublishedAt, nil
+}
+
+func (githubProvider) archiveRequest(ctx context.Context, c *Checker, e SourceEntry) (*http.Request, error) {
+	repoURL, err := c.githubRepoURL(e)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", repoURL+"/tarball/"+url.PathEscape(e.Tag), nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setGitHubAuth(req)
+	return req, nil
+}
+
+// githubRelease is the part of a release in the GitHub releases list that
+// sourcerer uses.
+type githubRelease struct {
+	TagName    string `json:"tag_name"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+}
+
+// releaseTags returns the tags of the releases listed at url, the releases
+// resource of a repo, and its following pages. Drafts are always skipped,
+// prereleases unless includePrerelease is set.
+func (c *Checker) releaseTags(ctx context.Context, url string, includePrerelease bool) ([]string, error) {
+	pages, err := c.githubFetchPages(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	all := []string{}
+	for _, body := range pages {
+		tags, err := parseReleaseTags(body, includePrerelease)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse body of url %s\n%v", url, err)
+		}
+		all = append(all, tags...)
+	}
+	return all, nil
+}
+
+// parseReleaseTags extracts the tags of the published releases in a GitHub
+// releases list body, skipping prereleases unless includePrerelease is set.
+func parseReleaseTags(body []byte, includePrerelease bool) ([]string, error) {
+	var releases []githubRelease
+	err := json.Unmarshal(body, &releases)
+	if err != nil {
+		return nil, fmt.Errorf("%v\n body:\n%s", err, string(body))
+	}
+	tags := []string{}
+	for _, r := range releases {
+		if r.Draft || (r.Prerelease && !includePrerelease) {
+			continue
+		}
+		tags = append(tags, r.TagName)
+	}
+	return tags, nil
+}
+
+// tagNames returns the names of the git tags listed at url, the tags resource
+// of a repo, and its following pages.
+func (c *Checker) tagNames(ctx context.Context, url string) ([]string, error) {
+	pages, err := c.githubFetchPages(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, body := range pages {
+		var tags []struct {
+			Name string `json:"name"`
+		}
+		err = json.Unmarshal(body, &tags)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse body of url %s\n%v\n body:\n%s", url, err, string(body))
+		}
+		for _, t := range tags {
+			names = append(names, t.Name)
+		}
+	}
+	return names, nil
+}
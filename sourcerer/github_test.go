@@ -0,0 +1,101 @@
+package sourcerer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLatestTag(t *testing.T) {
+	for _, c := range []struct {
+		body string
+		tag  string
+		ok   bool
+	}{
+		{`{"tag_name": "v1.2.3", "name": "Release 1.2.3", "draft": false}`, "v1.2.3", true},
+		{`{"name": "v1.2.3"}`, "v1.2.3", true},
+		{`{"tag_name": "v1.2.3", "name": ""}`, "v1.2.3", true},
+		{`{"tag_name": "v1.2.3", "name": "Stable"}`, "v1.2.3", true},
+		{`{}`, "", false},
+	} {
+		tag, ok, err := parseLatestTag([]byte(c.body))
+		if err != nil || tag != c.tag || ok != c.ok {
+			t.Errorf("parseLatestTag(%s) = %q, %v, %v; want %q, %v", c.body, tag, ok, err, c.tag, c.ok)
+		}
+	}
+}
+
+func TestParseLatestTagMalformed(t *testing.T) {
+	for _, body := range []string{`{"name": 123}`, `{"tag_name": ["v1.2.3"]}`, `{"name": "v1.2.3"`} {
+		if tag, _, err := parseLatestTag([]byte(body)); err == nil {
+			t.Errorf("parseLatestTag(%s) = %q, want an error", body, tag)
+		}
+	}
+}
+
+func TestParseReleaseTags(t *testing.T) {
+	body := []byte(`[
+		{"tag_name": "v2.1.0", "draft": true, "prerelease": false},
+		{"tag_name": "v2.0.0-rc.1", "draft": false, "prerelease": true},
+		{"tag_name": "v1.9.0", "draft": false, "prerelease": false},
+		{"tag_name": "v2.0.0-beta.1", "draft": true, "prerelease": true},
+		{"tag_name": "v1.8.0", "draft": false, "prerelease": false}
+	]`)
+	for _, c := range []struct {
+		includePrerelease bool
+		want              []string
+	}{
+		{false, []string{"v1.9.0", "v1.8.0"}},
+		{true, []string{"v2.0.0-rc.1", "v1.9.0", "v1.8.0"}},
+	} {
+		got, err := parseReleaseTags(body, c.includePrerelease)
+		if err != nil || !reflect.DeepEqual(got, c.want) {
+			t.Errorf("include prerelease %v: got %v, %v; want %v", c.includePrerelease, got, err, c.want)
+		}
+	}
+}
+
+func TestLatestFollowsLinkPages(t *testing.T) {
+	var s *httptest.Server
+	s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		page2 := req.URL.Query().Get("page") == "2"
+		if !page2 {
+			next := s.URL + req.URL.Path + "?per_page=100&page=2"
+			w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next", <%s>; rel="last"`, next, next))
+		}
+		switch {
+		case req.URL.Path == "/repos/org/lib/tags" && !page2:
+			w.Write([]byte(`[{"name": "v1.1.0"}, {"name": "v1.0.0"}]`))
+		case req.URL.Path == "/repos/org/lib/tags":
+			w.Write([]byte(`[{"name": "v2.0.0"}, {"name": "v0.9.0"}]`))
+		case req.URL.Path == "/repos/org/lib/releases" && !page2:
+			w.Write([]byte(`[{"tag_name": "v1.1.0"}, {"tag_name": "v1.0.0"}]`))
+		case req.URL.Path == "/repos/org/lib/releases":
+			w.Write([]byte(`[{"tag_name": "v2.0.0-rc.1", "prerelease": true}, {"tag_name": "v0.9.0"}]`))
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	defer s.Close()
+
+	repo := strings.TrimPrefix(s.URL, "http://") + "/org/lib"
+	for _, c := range []struct {
+		e        SourceEntry
+		maxPages int
+		want     string
+	}{
+		{SourceEntry{Repo: repo, Source: sourceTags}, 10, "v2.0.0"},
+		{SourceEntry{Repo: repo, Source: sourceTags}, 1, "v1.1.0"},
+		{SourceEntry{Repo: repo, IncludePrerelease: true}, 10, "v2.0.0-rc.1"},
+	} {
+		checker := testChecker()
+		checker.APIBase, checker.MaxPages = s.URL, c.maxPages
+		if got, err := checker.LookupLatest(context.Background(), c.e); err != nil || got != c.want {
+			t.Errorf("%+v with %d pages: got %q, %v; want %q", c.e, c.maxPages, got, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package sourcerer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const defaultGitLabAPIBase = "https://gitlab.com/api/v4"
+
+// gitlabProvider looks up releases and tags with the GitLab API. Repos are
+// written as gitlab.com/group/project, with any number of subgroups.
+type gitlabProvider struct{}
+
+// gitlabAPIBase returns the API base URL to use for e, preferring the entry's
+// own setting over $GITLAB_API_URL.
+func gitlabAPIBase(e SourceEntry) string {
+	base := e.APIBase
+	if base == "" {
+		base = os.Getenv("GITLAB_API_URL")
+	}
+	if base == "" {
+		base = defaultGitLabAPIBase
+	}
+	if !strings.Contains(base, "://") {
+		base = "https://" + base
+	}
+	return strings.TrimSuffix(base, "/")
+}
+
+// gitlabProjectURL returns the API URL of e's project.
+func gitlabProjectURL(e SourceEntry) (string, error) {
+	base := gitlabAPIBase(e)
+	host := "gitlab.com"
+	if u, err := url.Parse(base); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	project := strings.TrimPrefix(e.Repo, host+"/")
+	if project == e.Repo || strings.Count(project, "/") < 1 {
+		return "", fmt.Errorf("Could not parse: %s as a %s project", e.Repo, host)
+	}
+	return fmt.Sprintf("%s/projects/%s", base, url.PathEscape(project)), nil
+}
+
+func (gitlabProvider) lookupURL(c *Checker, e SourceEntry) (string, error) {
+	projectURL, err := gitlabProjectURL(e)
+	if err != nil {
+		return "", err
+	}
+	if e.Source == sourceTags {
+		return projectURL + "/repository/tags?per_page=100", nil
+	}
+	return projectURL + "/releases?per_page=100", nil
+}
+
+func (gitlabProvider) tagExists(ctx context.Context, c *Checker, e SourceEntry, tag string) (bool, error) {
+	projectURL, err := gitlabProjectURL(e)
+	if err != nil {
+		return false, err
+	}
+	ref := projectURL + "/releases/" + url.PathEscape(tag)
+	if e.Source == sourceTags {
+		ref = projectURL + "/repository/tags/" + url.PathEscape(tag)
+	}
+	req, err := gitlabRequest(ctx, ref)
+	if err != nil {
+		return false, err
+	}
+	res, err := c.doWithRetry(req)
+	if err != nil {
+		return false, err
+	}
+	res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+	return false, fmt.Errorf("unable to look up %s: %s", ref, res.Status)
+}
+
+func (gitlabProvider) archiveRequest(ctx context.Context, c *Checker, e SourceEntry) (*http.Request, error) {
+	projectURL, err := gitlabProjectURL(e)
+	if err != nil {
+		return nil, err
+	}
+	return gitlabRequest(ctx, projectURL+"/repository/archive.tar.gz?sha="+url.QueryEscape(e.Tag))
+}
+
+func (gitlabProvider) latest(ctx context.Context, c *Checker, e SourceEntry, url string) (string, bool, error) {
+	names := []string{}
+	for pages := 0; url != "" && pages < c.MaxPages; pages++ {
+		body, next, err := c.gitlabFetchPage(ctx, url)
+		if err != nil {
+			return "", false, err
+		}
+		var refs []struct {
+			Name    string `json:"name"`
+			TagName string `json:"tag_name"`
+		}
+		err = json.Unmarshal(body, &refs)
+		if err != nil {
+			return "", false, fmt.Errorf("unable to parse body of url %s\n%v\n body:\n%s", url, err, string(body))
+		}
+		for _, r := range refs {
+			// Releases carry the tag in tag_name, tags in name.
+			name := r.TagName
+			if e.Source == sourceTags {
+				name = r.Name
+			}
+			names = append(names, name)
+		}
+		url = next
+	}
+	tag, ok := highestVersion(e, names)
+	return tag, ok, nil
+}
+
+// gitlabRequest returns a GET request for url, authenticated with
+// $GITLAB_TOKEN when it is set.
+func gitlabRequest(ctx context.Context, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token := os.Getenv("GITLAB_TOKEN"); token != "" {
+		req.Header.Set("PRIVATE-TOKEN", token)
+	}
+	return req, nil
+}
+
+// gitlabFetchPage fetches a page of a GitLab API list, also returning the URL
+// of the next page: the rel="next" Link or, without one, pageURL with the
+// page in the X-Next-Page header. It is empty on the last page.
+func (c *Checker) gitlabFetchPage(ctx context.Context, pageURL string) (body []byte, next string, err error) {
+	req, err := gitlabRequest(ctx, pageURL)
+	if err != nil {
+		return nil, "", err
+	}
+	body, header, err := c.fetchWithHeader(req)
+	if err != nil {
+		return nil, "", err
+	}
+	if next = nextLink(header.Get("Link")); next == "" && header.Get("X-Next-Page") != "" {
+		u := *req.URL
+		q := u.Query()
+		q.Set("page", header.Get("X-Next-Page"))
+		u.RawQuery = q.Encode()
+		next = u.String()
+	}
+	return body, next, nil
+}
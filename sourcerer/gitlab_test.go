@@ -0,0 +1,51 @@
+package sourcerer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGitLabLatestFollowsNextPage(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("per_page") != "100" {
+			t.Errorf("%s does not ask for 100 per page", req.URL)
+		}
+		page2 := req.URL.Query().Get("page") == "2"
+		if !page2 {
+			w.Header().Set("X-Next-Page", "2")
+		}
+		switch {
+		case req.URL.Path == "/projects/org/lib/repository/tags" && !page2:
+			w.Write([]byte(`[{"name": "v1.1.0"}, {"name": "v1.0.0"}]`))
+		case req.URL.Path == "/projects/org/lib/repository/tags":
+			w.Write([]byte(`[{"name": "v2.0.0"}, {"name": "v0.9.0"}]`))
+		case req.URL.Path == "/projects/org/lib/releases" && !page2:
+			w.Write([]byte(`[{"tag_name": "v1.1.0"}]`))
+		case req.URL.Path == "/projects/org/lib/releases":
+			w.Write([]byte(`[{"tag_name": "v1.2.0"}]`))
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	defer s.Close()
+
+	repo := strings.TrimPrefix(s.URL, "http://") + "/org/lib"
+	for _, c := range []struct {
+		e        SourceEntry
+		maxPages int
+		want     string
+	}{
+		{SourceEntry{Repo: repo, Provider: ProviderGitLab, APIBase: s.URL, Source: sourceTags}, 10, "v2.0.0"},
+		{SourceEntry{Repo: repo, Provider: ProviderGitLab, APIBase: s.URL, Source: sourceTags}, 1, "v1.1.0"},
+		{SourceEntry{Repo: repo, Provider: ProviderGitLab, APIBase: s.URL}, 10, "v1.2.0"},
+	} {
+		checker := testChecker()
+		checker.MaxPages = c.maxPages
+		if got, err := checker.LookupLatest(context.Background(), c.e); err != nil || got != c.want {
+			t.Errorf("%+v with %d pages: got %q, %v; want %q", c.e, c.maxPages, got, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package sourcerer
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// limiter is a token bucket holding a single token, refilled every
+// interval, shared by all goroutines: requests waiting on it are spaced at
+// least interval apart.
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newLimiter(perSecond float64) *limiter {
+	return &limiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+// wait blocks until the next request may be made or ctx is done.
+func (l *limiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	select {
+	case <-time.After(time.Until(at)):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// defaultRate is the rate used when c.Rate is not set, more conservative
+// without a GitHub token, when limits are lower.
+func (c *Checker) defaultRate() float64 {
+	if c.githubToken(githubHost(defaultAPIBase)) != "" {
+		return 10
+	}
+	return 2
+}
+
+// Do performs req paced and retried like the requests of checks, such as a
+// download of the archive returned by ArchiveRequest.
+func (c *Checker) Do(req *http.Request) (*http.Response, error) {
+	return c.doWithRetry(req)
+}
+
+// doWithRetry performs req, paced by the limiter of c, retrying network errors
+// and 5xx responses with exponential backoff up to c.Retries times or until
+// req's context is done. The last error or 5xx response is then returned, as
+// are 4xx responses right away.
+func (c *Checker) doWithRetry(req *http.Request) (*http.Response, error) {
+	c.init()
+	delay := c.RetryDelay
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.wait(req.Context()); err != nil {
+			return nil, err
+		}
+		res, err := c.client().Do(req)
+		if err != nil {
+			c.debugf("GET %s: %v", req.URL, err)
+		} else {
+			c.debugf("GET %s: %s", req.URL, res.Status)
+		}
+		if err == nil && res.StatusCode < 500 {
+			return res, nil
+		}
+		if attempt >= c.Retries || req.Context().Err() != nil {
+			if err != nil {
+				return nil, err
+			}
+			return res, nil
+		}
+		if res != nil {
+			res.Body.Close()
+		}
+		c.warnf("retrying %s in %v", req.URL, delay)
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		delay *= 2
+	}
+}
+
+// fetch performs req and returns the response body. Any status other than
+// 200 OK is an error, a *notFoundError for 404 Not Found.
+func (c *Checker) fetch(req *http.Request) ([]byte, error) {
+	body, _, err := c.fetchWithHeader(req)
+	return body, err
+}
+
+// fetchWithHeader is fetch, also returning the header of the response, such
+// as the pagination headers of list endpoints.
+func (c *Checker) fetchWithHeader(req *http.Request) ([]byte, http.Header, error) {
+	res, err := c.doWithRetry(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read body of url %s\n%v", req.URL, err)
+	}
+	if res.StatusCode == http.StatusNotFound {
+		return nil, nil, &notFoundError{url: req.URL.String(), authenticated: authenticated(req)}
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("unexpected status %s from %s\n body:\n%s", res.Status, req.URL, string(body))
+	}
+	return body, res.Header, nil
+}
+
+// notFoundError describes a request for a resource, such as a renamed or
+// deleted repo or a release that does not exist, answered with 404 Not Found.
+// APIs also answer 404 for private repos the request may not see, so whether
+// it was authenticated tells what to suggest.
+type notFoundError struct {
+	url           string
+	authenticated bool
+}
+
+func (e *notFoundError) Error() string {
+	return fmt.Sprintf("%s was not found", e.url)
+}
+
+// hint suggests how to reach the resource if it exists but is private.
+func (e *notFoundError) hint() string {
+	if e.authenticated {
+		return "if the repo is private, ensure the token has access to it"
+	}
+	return "if the repo is private, authenticate with a token that has access to it"
+}
+
+// authenticated reports whether req carries credentials.
+func authenticated(req *http.Request) bool {
+	return req.Header.Get("Authorization") != "" || req.Header.Get("PRIVATE-TOKEN") != ""
+}
@@ -0,0 +1,30 @@
+package sourcerer
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimiterSpacesRequests(t *testing.T) {
+	l := newLimiter(20)
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if min := time.Duration(i) * l.interval; time.Since(start) < min {
+			t.Errorf("request %d was made after %v, want at least %v", i, time.Since(start), min)
+		}
+	}
+}
+
+func TestLimiterCanceled(t *testing.T) {
+	l := newLimiter(0.1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	l.wait(ctx)
+	if err := l.wait(ctx); err != context.Canceled {
+		t.Errorf("got %v waiting with a canceled context, want %v", err, context.Canceled)
+	}
+}
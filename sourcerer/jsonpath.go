@@ -0,0 +1,119 @@
+package sourcerer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// jsonPathProvider reads the version from an arbitrary JSON endpoint: the
+// entry's URL is fetched and its VersionPath evaluated against the body.
+type jsonPathProvider struct{}
+
+func (jsonPathProvider) lookupURL(c *Checker, e SourceEntry) (string, error) {
+	return e.URL, nil
+}
+
+func (jsonPathProvider) latest(ctx context.Context, c *Checker, e SourceEntry, url string) (string, bool, error) {
+	return c.fetchJSONPath(ctx, url, e.VersionPath)
+}
+
+// fetchJSONPath fetches the JSON document at url and returns the version found
+// at path in it. ok is false when path selects nothing.
+func (c *Checker) fetchJSONPath(ctx context.Context, url, path string) (version string, ok bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", false, err
+	}
+	body, err := c.fetch(req)
+	if err != nil {
+		return "", false, err
+	}
+	var doc interface{}
+	err = json.Unmarshal(body, &doc)
+	if err != nil {
+		return "", false, fmt.Errorf("unable to parse body of url %s\n%v\n body:\n%s", url, err, string(body))
+	}
+	v, err := evalJSONPath(doc, path)
+	if err != nil {
+		return "", false, fmt.Errorf("%s: %v", url, err)
+	}
+	switch v := v.(type) {
+	case nil:
+		return "", false, nil
+	case string:
+		return v, v != "", nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true, nil
+	}
+	return "", false, fmt.Errorf("%s: %s is not a string or number", url, path)
+}
+
+// jsonPathStepRE matches one step of the JSONPath subset we support: a
+// .field, a ['field'] or a [index].
+var jsonPathStepRE = regexp.MustCompile(`^(?:\.([^.\[]+)|\['([^']*)'\]|\[(\d+)\])`)
+
+type jsonPathStep struct {
+	field string
+	index int // -1 when the step selects a field
+}
+
+// parseJSONPath parses a JSONPath made only of field and index steps, such as
+// $.info.version or $.releases[0]['tag'].
+func parseJSONPath(path string) ([]jsonPathStep, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("invalid version path %q: must start with $", path)
+	}
+	steps := []jsonPathStep{}
+	rest := path[1:]
+	for rest != "" {
+		m := jsonPathStepRE.FindStringSubmatch(rest)
+		if m == nil {
+			return nil, fmt.Errorf("invalid version path %q at %q", path, rest)
+		}
+		switch {
+		case m[1] != "":
+			steps = append(steps, jsonPathStep{field: m[1], index: -1})
+		case m[3] != "":
+			i, _ := strconv.Atoi(m[3])
+			steps = append(steps, jsonPathStep{index: i})
+		default:
+			steps = append(steps, jsonPathStep{field: m[2], index: -1})
+		}
+		rest = rest[len(m[0]):]
+	}
+	return steps, nil
+}
+
+// evalJSONPath returns the value selected by path in doc, a document decoded
+// by encoding/json. A path leading to a missing field yields nil.
+func evalJSONPath(doc interface{}, path string) (interface{}, error) {
+	steps, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	v := doc
+	for _, s := range steps {
+		if s.index >= 0 {
+			arr, ok := v.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s: cannot index a non-array", path)
+			}
+			if s.index >= len(arr) {
+				return nil, nil
+			}
+			v = arr[s.index]
+			continue
+		}
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: cannot select field %q of a non-object", path, s.field)
+		}
+		v = obj[s.field]
+	}
+	return v, nil
+}
@@ -0,0 +1,75 @@
+package sourcerer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// latestCall is a lookup of the latest version shared by every entry asking
+// for the same thing during a run. done is closed once the result is set.
+type latestCall struct {
+	done chan struct{}
+	tag  string
+	ok   bool
+	err  error
+}
+
+// latestMemo holds the lookups of the current run so that sources referenced
+// by several entries or manifests are only looked up once.
+type latestMemo struct {
+	sync.Mutex
+	calls map[string]*latestCall
+	hits  int
+}
+
+// ResetMemo forgets the lookups of the previous run, so every watch cycle
+// sees upstream afresh.
+func (c *Checker) ResetMemo() {
+	c.memo.Lock()
+	defer c.memo.Unlock()
+	c.memo.calls = map[string]*latestCall{}
+	c.memo.hits = 0
+}
+
+// MemoHits returns the number of lookups reused in the current run.
+func (c *Checker) MemoHits() int {
+	c.memo.Lock()
+	defer c.memo.Unlock()
+	return c.memo.hits
+}
+
+// memoLatest returns p.latest(ctx, c, e, url), reusing the result of an earlier
+// or in-flight lookup of the same source. The key is the provider and url,
+// which identify the host, repo and source type, along with the settings of
+// e that decide which upstream version is the latest.
+func (c *Checker) memoLatest(ctx context.Context, p provider, e SourceEntry, url string) (string, bool, error) {
+	key := fmt.Sprintf("%T %s %q %q %q %q %t %t %q", p, url, e.VersionPath, e.TagPrefix, e.TagPattern, e.TagComponent, e.StableOnly, e.IncludePrerelease, e.Versioning)
+	if parts, ok := e.trackedParts(); ok {
+		key += fmt.Sprintf(" %v", parts)
+	}
+	c.memo.Lock()
+	if c.memo.calls == nil {
+		c.memo.calls = map[string]*latestCall{}
+	}
+	call, found := c.memo.calls[key]
+	if found {
+		c.memo.hits++
+	} else {
+		call = &latestCall{done: make(chan struct{})}
+		c.memo.calls[key] = call
+	}
+	c.memo.Unlock()
+
+	if !found {
+		call.tag, call.ok, call.err = p.latest(ctx, c, e, url)
+		close(call.done)
+		return call.tag, call.ok, call.err
+	}
+	select {
+	case <-call.done:
+		return call.tag, call.ok, call.err
+	case <-ctx.Done():
+		return "", false, ctx.Err()
+	}
+}
@@ -0,0 +1,107 @@
+package sourcerer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// netrcMachine is the login and password of one machine in a netrc file.
+type netrcMachine struct {
+	login, password string
+}
+
+var (
+	netrcOnce     sync.Once
+	netrcMachines map[string]netrcMachine
+)
+
+// netrcPath returns the netrc file to read: $NETRC, or ~/.netrc.
+func netrcPath() string {
+	if p := os.Getenv("NETRC"); p != "" {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".netrc")
+}
+
+// netrcLookup returns the credentials netrc has for the first of hosts it
+// lists. Its default entry is not used, so that a token is never sent to a
+// host it was not given for. The file is read once; a missing or unreadable
+// file has no credentials.
+func netrcLookup(hosts ...string) (netrcMachine, bool) {
+	netrcOnce.Do(func() {
+		netrcMachines = map[string]netrcMachine{}
+		if p := netrcPath(); p != "" {
+			if data, err := ioutil.ReadFile(p); err == nil {
+				netrcMachines = parseNetrc(string(data))
+			}
+		}
+	})
+	for _, h := range hosts {
+		if m, ok := netrcMachines[h]; ok {
+			return m, true
+		}
+	}
+	return netrcMachine{}, false
+}
+
+// parseNetrc returns the machines of a netrc file by name. The default entry,
+// comments and macro definitions are skipped.
+func parseNetrc(data string) map[string]netrcMachine {
+	var tokens []string
+	inMacro := false
+	for _, l := range strings.Split(data, "\n") {
+		if inMacro {
+			// A macro runs until the next empty line.
+			inMacro = strings.TrimSpace(l) != ""
+			continue
+		}
+		for _, f := range strings.Fields(l) {
+			if strings.HasPrefix(f, "#") {
+				break
+			}
+			tokens = append(tokens, f)
+			if f == "macdef" {
+				inMacro = true
+				break
+			}
+		}
+	}
+
+	machines := map[string]netrcMachine{}
+	name, current := "", (*netrcMachine)(nil)
+	for i := 0; i < len(tokens); i++ {
+		value := ""
+		if i+1 < len(tokens) {
+			value = tokens[i+1]
+		}
+		switch tokens[i] {
+		case "machine", "default", "macdef":
+			if current != nil {
+				machines[name] = *current
+			}
+			current = nil
+			if tokens[i] == "machine" {
+				name, current = value, &netrcMachine{}
+				i++
+			}
+		case "login", "password", "account":
+			if current != nil && tokens[i] == "login" {
+				current.login = value
+			} else if current != nil && tokens[i] == "password" {
+				current.password = value
+			}
+			i++
+		}
+	}
+	if current != nil {
+		machines[name] = *current
+	}
+	return machines
+}
@@ -0,0 +1,23 @@
+package sourcerer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseNetrcSkipsDefault(t *testing.T) {
+	got := parseNetrc(`# credentials
+machine github.com login user password secret
+default login anyone password fallback
+machine gitlab.com
+	login other
+	password token
+`)
+	want := map[string]netrcMachine{
+		"github.com": {login: "user", password: "secret"},
+		"gitlab.com": {login: "other", password: "token"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,138 @@
+package sourcerer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// provider looks up the latest version of a source from the registry hosting
+// it, making requests with c.
+type provider interface {
+	// lookupURL returns the URL queried for e's latest version.
+	lookupURL(c *Checker, e SourceEntry) (string, error)
+	// latest fetches url, as returned by lookupURL, and returns the latest
+	// version found there. ok is false when upstream does not define one.
+	latest(ctx context.Context, c *Checker, e SourceEntry, url string) (tag string, ok bool, err error)
+}
+
+// tagChecker is implemented by providers that can tell whether a tag still
+// exists upstream, to catch pinned versions that were deleted or yanked.
+type tagChecker interface {
+	// tagExists reports whether tag exists as what e is checked against: a
+	// release, or a git tag for tags sources.
+	tagExists(ctx context.Context, c *Checker, e SourceEntry, tag string) (bool, error)
+}
+
+// headChecker is implemented by providers that can look up the head commit
+// of a branch.
+type headChecker interface {
+	// head returns the SHA of the latest commit of e's Branch.
+	head(ctx context.Context, c *Checker, e SourceEntry) (string, error)
+}
+
+// releaseDater is implemented by providers that can tell when a version was
+// published.
+type releaseDater interface {
+	// published returns when tag was released, or for tags sources when its
+	// commit was made.
+	published(ctx context.Context, c *Checker, e SourceEntry, tag string) (time.Time, error)
+}
+
+// archiver is implemented by providers that serve a source archive of a tag.
+type archiver interface {
+	// archiveRequest returns the request downloading the archive of e's Tag.
+	archiveRequest(ctx context.Context, c *Checker, e SourceEntry) (*http.Request, error)
+}
+
+// Names of the providers a source may set.
+const (
+	ProviderGitHub    = "github"
+	ProviderGitLab    = "gitlab"
+	ProviderBitbucket = "bitbucket"
+	ProviderPyPI      = "pypi"
+	ProviderNPM       = "npm"
+	ProviderExec      = "exec"
+)
+
+var providers = map[string]provider{
+	ProviderGitHub:    githubProvider{},
+	ProviderGitLab:    gitlabProvider{},
+	ProviderBitbucket: bitbucketProvider{},
+	ProviderPyPI:      pypiProvider{},
+	ProviderNPM:       npmProvider{},
+	ProviderExec:      execProvider{},
+}
+
+// hostProviders maps the hosts of repos to the provider of sources on them
+// that do not set one.
+var hostProviders = map[string]string{
+	"github.com":    ProviderGitHub,
+	"gitlab.com":    ProviderGitLab,
+	"bitbucket.org": ProviderBitbucket,
+}
+
+// repoHost returns the host of a repo such as github.com/owner/name.
+func repoHost(repo string) string {
+	if m := repoRE.FindStringSubmatch(repo); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// providerNames returns the names of the providers in alphabetical order.
+func providerNames() []string {
+	names := []string{}
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isRegistryProvider reports whether the named provider looks up packages by
+// Name rather than by Repo.
+func isRegistryProvider(name string) bool {
+	return name == ProviderPyPI || name == ProviderNPM
+}
+
+// providerFor returns the provider e is checked with.
+func providerFor(e SourceEntry) (provider, error) {
+	if e.VersionPath != "" {
+		return jsonPathProvider{}, nil
+	}
+	name := e.Provider
+	if name == "" {
+		name = ProviderGitHub
+	}
+	p, ok := providers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q for %s", e.Provider, e.Label())
+	}
+	return p, nil
+}
+
+// LookupURL returns the URL queried for e's latest version.
+func (c *Checker) LookupURL(e SourceEntry) (string, error) {
+	p, err := providerFor(e)
+	if err != nil {
+		return "", err
+	}
+	return p.lookupURL(c, e)
+}
+
+// ArchiveRequest returns the request downloading the source archive of e's
+// Tag, for providers serving one.
+func (c *Checker) ArchiveRequest(ctx context.Context, e SourceEntry) (*http.Request, error) {
+	p, err := providerFor(e)
+	if err != nil {
+		return nil, err
+	}
+	a, ok := p.(archiver)
+	if !ok {
+		return nil, fmt.Errorf("%s: %s sources cannot be downloaded", e.Label(), e.Provider)
+	}
+	return a.archiveRequest(ctx, c, e)
+}
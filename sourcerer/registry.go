@@ -0,0 +1,49 @@
+package sourcerer
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	defaultPyPIBase = "https://pypi.org"
+	defaultNPMBase  = "https://registry.npmjs.org"
+)
+
+// registryBase returns e's API base URL, or def when the entry sets none.
+func registryBase(e SourceEntry, def string) string {
+	base := e.APIBase
+	if base == "" {
+		base = def
+	}
+	if !strings.Contains(base, "://") {
+		base = "https://" + base
+	}
+	return strings.TrimSuffix(base, "/")
+}
+
+// pypiProvider looks up the latest version of a Python package on PyPI.
+type pypiProvider struct{}
+
+func (pypiProvider) lookupURL(c *Checker, e SourceEntry) (string, error) {
+	return fmt.Sprintf("%s/pypi/%s/json", registryBase(e, defaultPyPIBase), url.PathEscape(e.Name)), nil
+}
+
+func (pypiProvider) latest(ctx context.Context, c *Checker, e SourceEntry, url string) (string, bool, error) {
+	return c.fetchJSONPath(ctx, url, "$.info.version")
+}
+
+// npmProvider looks up the version tagged latest of a package on the npm
+// registry.
+type npmProvider struct{}
+
+func (npmProvider) lookupURL(c *Checker, e SourceEntry) (string, error) {
+	// Scoped packages, @scope/name, have their / escaped.
+	return fmt.Sprintf("%s/%s", registryBase(e, defaultNPMBase), url.PathEscape(e.Name)), nil
+}
+
+func (npmProvider) latest(ctx context.Context, c *Checker, e SourceEntry, url string) (string, bool, error) {
+	return c.fetchJSONPath(ctx, url, "$['dist-tags'].latest")
+}
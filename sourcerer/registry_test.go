@@ -0,0 +1,35 @@
+package sourcerer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegistryLatest(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.EscapedPath() {
+		case "/pypi/requests/json":
+			w.Write([]byte(`{"info": {"name": "requests", "version": "2.31.0"}, "releases": {"2.30.0": [], "2.31.0": []}}`))
+		case "/@types%2Fnode":
+			w.Write([]byte(`{"name": "@types/node", "dist-tags": {"latest": "20.10.5", "next": "21.0.0-beta.1"}}`))
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	defer s.Close()
+
+	checker := testChecker()
+	for _, c := range []struct {
+		e    SourceEntry
+		want string
+	}{
+		{SourceEntry{Provider: ProviderPyPI, Name: "requests", APIBase: s.URL}, "2.31.0"},
+		{SourceEntry{Provider: ProviderNPM, Name: "@types/node", APIBase: s.URL}, "20.10.5"},
+	} {
+		if got, err := checker.LookupLatest(context.Background(), c.e); err != nil || got != c.want {
+			t.Errorf("%s %s: got %q, %v; want %q", c.e.Provider, c.e.Name, got, err, c.want)
+		}
+	}
+}
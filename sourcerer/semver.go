@@ -0,0 +1,165 @@
+package sourcerer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var semverRE = regexp.MustCompile(`^\D*(?P<first>(\d+))(\.(?P<second>\d+))?(\.(?P<third>\d+))?(\.(?P<fourth>\d+))?(\.(?P<fifth>\d+))?(-(?P<prerelease>[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*))?(\+(?P<build>[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*))?$`)
+
+// semver is a parsed version: its dotted numeric parts and any prerelease
+// identifiers. Build metadata is dropped as it plays no part in ordering.
+type semver struct {
+	parts      []int
+	prerelease []string
+}
+
+func mkSemver(s string) (semver, error) {
+	names := semverRE.SubexpNames()
+	m := semverRE.FindStringSubmatch(s)
+	out := semver{parts: []int{}}
+	if m == nil {
+		return out, fmt.Errorf("could not parse %s as semver, it has no numeric version", s)
+	}
+	for i, n := range names {
+		if n == "" || len(m) <= i || m[i] == "" {
+			continue
+		}
+		switch n {
+		case "prerelease":
+			out.prerelease = strings.Split(m[i], ".")
+		case "build":
+		default:
+			part, err := strconv.ParseInt(m[i], 10, 32)
+			if err != nil {
+				return out, fmt.Errorf("could not parse %s as semver\n%v", s, err)
+			}
+			if part < 0 {
+				return out, fmt.Errorf("could not parse %s as semver, one part was < 0", s)
+			}
+			out.parts = append(out.parts, int(part))
+		}
+	}
+	return out, nil
+}
+
+// CompareSemver returns -1, 0 or 1 as x is lower than, equal to or higher
+// than y. Missing numeric parts count as 0, so 1.2 equals 1.2.0.
+func CompareSemver(x, y string) (int, error) {
+	xv, err1 := mkSemver(x)
+	yv, err2 := mkSemver(y)
+	if err1 != nil || err2 != nil {
+		return 0, fmt.Errorf("Error comparing semver:\n%v\n%v", err1, err2)
+	}
+	xs, ys := xv.parts, yv.parts
+	for i := 0; i < len(xs) || i < len(ys); i++ {
+		var a, b int
+		if i < len(xs) {
+			a = xs[i]
+		}
+		if i < len(ys) {
+			b = ys[i]
+		}
+		if a > b {
+			return 1, nil
+		} else if a < b {
+			return -1, nil
+		}
+	}
+	return comparePrerelease(xv.prerelease, yv.prerelease), nil
+}
+
+// Bump levels: the index of the version part a bump changes first.
+// BumpUnknown is the level of versions without such parts, such as calendar
+// versions.
+const (
+	BumpMajor = iota
+	BumpMinor
+	BumpPatch
+	BumpUnknown
+)
+
+// bumpNames names the bump levels in messages.
+var bumpNames = []string{"major", "minor", "patch", "unknown"}
+
+// semverBump returns which part differs first between x and y: BumpMajor,
+// BumpMinor or BumpPatch. Differences after the patch, such as a fourth
+// part or only the prerelease, count as a patch bump. -1 is returned when the
+// versions are equal.
+func semverBump(x, y string) (int, error) {
+	xv, err1 := mkSemver(x)
+	yv, err2 := mkSemver(y)
+	if err1 != nil || err2 != nil {
+		return 0, fmt.Errorf("Error comparing semver:\n%v\n%v", err1, err2)
+	}
+	for i := 0; i < len(xv.parts) || i < len(yv.parts); i++ {
+		var a, b int
+		if i < len(xv.parts) {
+			a = xv.parts[i]
+		}
+		if i < len(yv.parts) {
+			b = yv.parts[i]
+		}
+		if a != b {
+			if i > 2 {
+				return 2, nil
+			}
+			return i, nil
+		}
+	}
+	if comparePrerelease(xv.prerelease, yv.prerelease) != 0 {
+		return 2, nil
+	}
+	return -1, nil
+}
+
+// isPrerelease reports whether the version s has a prerelease suffix.
+func isPrerelease(s string) bool {
+	v, err := mkSemver(s)
+	return err == nil && len(v.prerelease) > 0
+}
+
+// comparePrerelease orders prerelease identifiers per semver 2.0.0: a version
+// without a prerelease is higher than one with, numeric identifiers compare
+// numerically and sort before alphanumeric ones, and a longer list of
+// otherwise equal identifiers is higher.
+func comparePrerelease(x, y []string) int {
+	switch {
+	case len(x) == 0 && len(y) == 0:
+		return 0
+	case len(x) == 0:
+		return 1
+	case len(y) == 0:
+		return -1
+	}
+	for i := 0; i < len(x) && i < len(y); i++ {
+		xn, xErr := strconv.ParseUint(x[i], 10, 64)
+		yn, yErr := strconv.ParseUint(y[i], 10, 64)
+		switch {
+		case xErr == nil && yErr == nil:
+			if xn != yn {
+				if xn < yn {
+					return -1
+				}
+				return 1
+			}
+		case xErr == nil:
+			return -1
+		case yErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(x[i], y[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	switch {
+	case len(x) < len(y):
+		return -1
+	case len(x) > len(y):
+		return 1
+	}
+	return 0
+}
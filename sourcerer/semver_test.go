@@ -0,0 +1,86 @@
+package sourcerer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestComparePrerelease(t *testing.T) {
+	// Each version is lower than the next.
+	order := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0"}
+	for i := 0; i+1 < len(order); i++ {
+		x, y := order[i], order[i+1]
+		if got, err := CompareSemver(x, y); err != nil || got != -1 {
+			t.Errorf("CompareSemver(%s, %s) = %d, %v; want -1", x, y, got, err)
+		}
+		if got, err := CompareSemver(y, x); err != nil || got != 1 {
+			t.Errorf("CompareSemver(%s, %s) = %d, %v; want 1", y, x, got, err)
+		}
+	}
+	for _, c := range []struct {
+		x, y string
+		want int
+	}{
+		{"alpha", "alpha", 0},
+		{"1", "alpha", -1},
+		{"alpha.1", "alpha", 1},
+		{"", "alpha", 1},
+	} {
+		var x, y []string
+		if c.x != "" {
+			x = strings.Split(c.x, ".")
+		}
+		if c.y != "" {
+			y = strings.Split(c.y, ".")
+		}
+		if got := comparePrerelease(x, y); got != c.want {
+			t.Errorf("comparePrerelease(%q, %q) = %d, want %d", c.x, c.y, got, c.want)
+		}
+	}
+}
+
+func TestCompareSemver(t *testing.T) {
+	for _, c := range []struct {
+		x, y string
+		want int
+	}{
+		{"1.2", "1.2.0", 0},
+		{"1.2", "1.2.1", -1},
+		{"1.2.1", "1.2", 1},
+		{"v1", "1.0.0", 0},
+		{"1.10.0", "1.9.0", 1},
+		{"1.2.3.4", "1.2.3", 1},
+		{"1.0.0+build.5", "1.0.0", 0},
+	} {
+		if got, err := CompareSemver(c.x, c.y); err != nil || got != c.want {
+			t.Errorf("CompareSemver(%s, %s) = %d, %v; want %d", c.x, c.y, got, err, c.want)
+		}
+	}
+}
+
+func TestMkSemverWithoutVersion(t *testing.T) {
+	for _, s := range []string{"latest", "", "v"} {
+		if v, err := mkSemver(s); err == nil {
+			t.Errorf("mkSemver(%q) = %v, want an error", s, v.parts)
+		}
+	}
+}
+
+func TestSemverBump(t *testing.T) {
+	for _, c := range []struct {
+		x, y string
+		want int
+	}{
+		{"1.2.3", "1.2.9", BumpPatch},
+		{"1.2.3", "1.3.0", BumpMinor},
+		{"1.2.3", "2.0.0", BumpMajor},
+		{"v1.2", "v1.2.1", BumpPatch},
+		{"1.2.3", "1.2.3.1", BumpPatch},
+		{"1.2.3-rc.1", "1.2.3", BumpPatch},
+		{"1.2.3", "1.2.3", -1},
+	} {
+		if got, err := semverBump(c.x, c.y); err != nil || got != c.want {
+			t.Errorf("semverBump(%s, %s) = %d, %v; want %d", c.x, c.y, got, err, c.want)
+		}
+	}
+}
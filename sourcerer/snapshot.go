@@ -0,0 +1,122 @@
+package sourcerer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Snapshot is the latest version of each source, keyed by SnapshotKey, as
+// recorded at Time. Checking against one needs no network access.
+type Snapshot struct {
+	Time    time.Time                 `json:"time"`
+	Sources map[string]SnapshotSource `json:"sources"`
+}
+
+// SnapshotSource is the latest version of a source in a Snapshot: a tag, or
+// a commit for branches.
+type SnapshotSource struct {
+	Provider string `json:"provider,omitempty"`
+	Latest   string `json:"latest"`
+}
+
+// LoadSnapshot reads the Snapshot saved as JSON in filename.
+func LoadSnapshot(filename string) (*Snapshot, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read snapshot %s\n%v", filename, err)
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("Invalid snapshot %s\n%v", filename, err)
+	}
+	return &s, nil
+}
+
+// latest returns the version recorded for e, a commit for branches.
+func (s *Snapshot) latest(e SourceEntry) (string, bool) {
+	src, ok := s.Sources[SnapshotKey(e)]
+	return src.Latest, ok && src.Latest != ""
+}
+
+// SnapshotKey identifies the latest version e looks up: its label, followed
+// by the branch it follows, the settings deciding which upstream version is
+// the latest that are not left to their defaults, as name=value, and for a
+// tracked release line the line. Like the key of memoLatest, it tells apart
+// e.g. two tag_components of one repo.
+func SnapshotKey(e SourceEntry) string {
+	key := e.Label()
+	if e.Branch != "" {
+		key += "@" + e.Branch
+	}
+	r := e.Resolved()
+	for _, s := range []struct {
+		name, value, def string
+	}{
+		{"provider", r.Provider, ProviderGitHub},
+		{"api_base", e.APIBase, ""},
+		{"source", r.Source, sourceReleases},
+		{"version_path", e.VersionPath, ""},
+		{"tag_prefix", e.TagPrefix, ""},
+		{"tag_pattern", e.TagPattern, ""},
+		{"tag_component", e.TagComponent, ""},
+		{"versioning", r.Versioning, versioningSemver},
+	} {
+		if s.value != "" && s.value != s.def {
+			key += " " + s.name + "=" + s.value
+		}
+	}
+	if e.StableOnly {
+		key += " stable_only"
+	}
+	if e.IncludePrerelease {
+		key += " include_prerelease"
+	}
+	if e.Branch != "" {
+		return key
+	}
+	if parts, ok := e.trackedParts(); ok {
+		line := []string{}
+		for _, p := range parts {
+			line = append(line, strconv.Itoa(p))
+		}
+		key += " " + strings.Join(line, ".") + ".x"
+	}
+	return key
+}
+
+// LookupLatest returns the latest version of e, or the head commit of its
+// branch, from its provider.
+func (c *Checker) LookupLatest(ctx context.Context, e SourceEntry) (string, error) {
+	p, err := providerFor(e)
+	if err != nil {
+		return "", err
+	}
+	if e.Branch != "" {
+		hc, ok := p.(headChecker)
+		if !ok {
+			return "", fmt.Errorf("branches of %s sources cannot be checked", e.Provider)
+		}
+		head, err := hc.head(ctx, c, e)
+		if err != nil {
+			return "", fmt.Errorf("There was an error retrieving the head of branch %s of %s\n%v", e.Branch, e.Label(), err)
+		}
+		return head, nil
+	}
+	url, err := p.lookupURL(c, e)
+	if err != nil {
+		return "", err
+	}
+	tag, ok, err := c.memoLatest(ctx, p, e, url)
+	if err != nil {
+		return "", fmt.Errorf("There was an error retrieving the latest version of %s\n%v", e.Label(), err)
+	}
+	if !ok {
+		return "", fmt.Errorf("The latest %s of %s is undefined", e.sourceKind(), e.Label())
+	}
+	return tag, nil
+}
@@ -0,0 +1,329 @@
+// Package sourcerer checks the sources pinned by manifests against the latest
+// versions of their upstreams. A Checker holds the settings of a check: parse
+// a manifest with ParseConfig, then check its sources with CheckNewer.
+package sourcerer
+
+import (
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// SourceEntry is a source of a manifest: an upstream repo, package or url and
+// the version of it that is pinned.
+type SourceEntry struct {
+	Repo string `yaml:"repo,omitempty" json:"repo" toml:"repo"`
+	// Tag is the pinned version, or a constraint such as ">=1.4, <2.0" that
+	// the latest version must satisfy.
+	Tag string `yaml:"tag,omitempty" json:"tag" toml:"tag"`
+	// Tags pins several release lines of the same upstream instead of a
+	// single Tag; see AllPins.
+	Tags []string `yaml:"tags,omitempty" json:"tags" toml:"tags"`
+	URL  string   `yaml:"url,omitempty" json:"url" toml:"url"`
+	// Name is the package name for registry providers such as pypi and npm,
+	// used instead of Repo. For other sources it overrides the name shown in
+	// output and rendered into artifact filenames.
+	Name string `yaml:"name,omitempty" json:"name" toml:"name"`
+	// Ext is the extension of the artifact filename of the source, tar.gz
+	// by default.
+	Ext string `yaml:"ext,omitempty" json:"ext" toml:"ext"`
+	// VersionPath is a JSONPath such as $.info.version locating the latest
+	// version in the JSON document served at URL. Without it a URL entry
+	// cannot be checked.
+	VersionPath string `yaml:"version_path,omitempty" json:"version_path" toml:"version_path"`
+	// Source selects what the Tag is compared against: the latest release
+	// (the default) or the highest semver git tag.
+	Source string `yaml:"source,omitempty" json:"source" toml:"source"`
+	// APIBase overrides the provider's API base URL for this entry, e.g.
+	// github.mycorp.com/api/v3 for GitHub Enterprise.
+	APIBase string `yaml:"api_base,omitempty" json:"api_base" toml:"api_base"`
+	// Provider names the registry the repo is hosted on, one of the Provider
+	// constants; GitHub is the default.
+	Provider string `yaml:"provider,omitempty" json:"provider" toml:"provider"`
+	// TagPrefix is stripped from tags to find their version, e.g. release-
+	// for tags like release-1.2.3. Tags without the prefix are ignored.
+	TagPrefix string `yaml:"tag_prefix,omitempty" json:"tag_prefix" toml:"tag_prefix"`
+	// TagPattern is a regexp whose group named version, or else its first
+	// group, captures the version of a tag, for naming schemes a prefix
+	// cannot describe. Tags that do not
+	// match are ignored.
+	TagPattern string `yaml:"tag_pattern,omitempty" json:"tag_pattern" toml:"tag_pattern"`
+	// TagComponent is the path of a module in a monorepo whose tags, like
+	// api/v1.2.3 for api, are compared. Tags of other modules are ignored.
+	TagComponent string `yaml:"tag_component,omitempty" json:"tag_component" toml:"tag_component"`
+	// Versioning is the scheme versions are compared by: semver (the default),
+	// calver for calendar versions such as 2024.03.1 or lexical to compare
+	// them as strings.
+	Versioning string `yaml:"versioning,omitempty" json:"versioning" toml:"versioning"`
+	// Track limits the versions compared against to a release line: 1.x or
+	// 1.4.x, or major or minor for the line of the pinned Tag.
+	Track string `yaml:"track,omitempty" json:"track" toml:"track"`
+	// StableOnly ignores prerelease versions when looking for the latest.
+	StableOnly bool `yaml:"stable_only,omitempty" json:"stable_only" toml:"stable_only"`
+	// IncludePrerelease compares against every published GitHub release,
+	// including those marked as prereleases, rather than only the latest
+	// stable one.
+	IncludePrerelease bool `yaml:"include_prerelease,omitempty" json:"include_prerelease" toml:"include_prerelease"`
+	// Branch and Commit track a moving branch instead of a Tag: the source
+	// is reported when the branch head is no longer Commit.
+	Branch string `yaml:"branch,omitempty" json:"branch" toml:"branch"`
+	Commit string `yaml:"commit,omitempty" json:"commit" toml:"commit"`
+	// Asset names a release asset of the Tag whose SHA256 is verified
+	// against SHA256, to catch re-tagged or tampered releases.
+	Asset  string `yaml:"asset,omitempty" json:"asset" toml:"asset"`
+	SHA256 string `yaml:"sha256,omitempty" json:"sha256" toml:"sha256"`
+	// LatestAsset names a release asset that the latest release must have,
+	// checked instead of a Tag for sources publishing rolling artifacts.
+	LatestAsset string `yaml:"latest_asset,omitempty" json:"latest_asset" toml:"latest_asset"`
+	// Command is the shell command run by the exec provider to print the
+	// latest version.
+	Command string `yaml:"command,omitempty" json:"command" toml:"command"`
+	// EOLProduct is the endoflife.date product whose release cycles tell
+	// whether the Tag is end-of-life.
+	EOLProduct string `yaml:"eol_product,omitempty" json:"eol_product" toml:"eol_product"`
+	// Pinned marks the Tag as intentionally held back: the entry is reported
+	// as pinned instead of outdated and never fails the run.
+	Pinned bool `yaml:"pinned,omitempty" json:"pinned" toml:"pinned"`
+}
+
+const (
+	sourceReleases = "releases"
+	sourceTags     = "tags"
+)
+
+// Label identifies the entry in messages: its name, repo or url.
+func (e SourceEntry) Label() string {
+	switch {
+	case e.Name != "":
+		return e.Name
+	case e.Repo != "":
+		return e.Repo
+	}
+	return e.URL
+}
+
+// sourceKind names what the entry is checked against, for use in messages.
+func (e SourceEntry) sourceKind() string {
+	if e.Source == sourceTags {
+		return "tag"
+	}
+	return "release"
+}
+
+// Resolved returns e with the implicit settings that decide how it is checked
+// filled in: its provider, what it is compared against and its versioning.
+func (e SourceEntry) Resolved() SourceEntry {
+	if e.URL != "" {
+		// Raw URLs are checked by VersionPath or not at all.
+		return e
+	}
+	if e.Provider == "" {
+		e.Provider = ProviderGitHub
+	}
+	if e.Source == "" && e.Branch == "" && !isRegistryProvider(e.Provider) && e.Provider != ProviderExec {
+		e.Source = sourceReleases
+	}
+	if e.Versioning == "" {
+		e.Versioning = versioningSemver
+	}
+	return e
+}
+
+// Config is a manifest: the sources it pins and the defaults applied to them.
+type Config struct {
+	// Defaults holds settings applied to every source that does not set
+	// them itself; see applyDefaults.
+	Defaults SourceEntry   `yaml:"defaults,omitempty" json:"defaults" toml:"defaults"`
+	Sources  []SourceEntry `yaml:"sources" json:"sources" toml:"sources"`
+}
+
+// Status is how a source compares with its upstream.
+type Status string
+
+const (
+	StatusUpToDate Status = "uptodate"
+	StatusOutdated Status = "outdated"
+	StatusUnknown  Status = "unknown"
+	StatusRaw      Status = "raw"
+	StatusPinned   Status = "pinned"
+	// StatusMissing means the pinned tag no longer exists upstream.
+	StatusMissing Status = "missing"
+	// StatusMismatch means the release asset no longer has the recorded
+	// SHA256.
+	StatusMismatch Status = "mismatch"
+	// StatusAdvanced means the branch has commits after the recorded one.
+	StatusAdvanced Status = "advanced"
+	// StatusNotFound means the repo, or what it is checked against, does not
+	// exist upstream.
+	StatusNotFound Status = "notfound"
+	// StatusEOL means the release cycle of the pinned tag is past its end of
+	// life.
+	StatusEOL Status = "eol"
+)
+
+// Result is the outcome of checking a single SourceEntry. It holds no
+// presentation, which is left to the caller.
+type Result struct {
+	Manifest string `json:"manifest"`
+	Repo     string `json:"repo,omitempty"`
+	Name     string `json:"name,omitempty"`
+	URL      string `json:"url,omitempty"`
+	// Provider is the provider the source was checked with, empty for raw
+	// urls.
+	Provider string `json:"provider,omitempty"`
+	// Branch is set for sources tracking a branch, whose CurrentTag and
+	// LatestTag are commits.
+	Branch string `json:"branch,omitempty"`
+	// Track is the release line the source was compared against, if any.
+	Track      string `json:"track,omitempty"`
+	CurrentTag string `json:"currentTag,omitempty"`
+	LatestTag  string `json:"latestTag,omitempty"`
+	// LatestPublished is when LatestTag was published, in RFC 3339, and
+	// LatestAgeDays how many days ago. They are only looked up for outdated
+	// sources.
+	LatestPublished string `json:"latestPublished,omitempty"`
+	LatestAgeDays   int    `json:"latestAgeDays,omitempty"`
+	// Asset, WantSHA256 and GotSHA256 describe a mismatched release asset.
+	Asset      string `json:"asset,omitempty"`
+	WantSHA256 string `json:"wantSha256,omitempty"`
+	GotSHA256  string `json:"gotSha256,omitempty"`
+	// EOLCycle and EOLDate are the end-of-life release cycle of the tag and
+	// the date it reached its end of life, if the product gives one.
+	EOLCycle string `json:"eolCycle,omitempty"`
+	EOLDate  string `json:"eolDate,omitempty"`
+	Status   Status `json:"status"`
+	// Detail says why an unknown source could not be checked, what was not
+	// found of a notfound one, or why a newer version left a source up to
+	// date.
+	Detail string `json:"detail,omitempty"`
+	// Hint suggests how to fix a notfound source.
+	Hint string `json:"hint,omitempty"`
+
+	// Index is the position of the entry in its manifest's sources, and
+	// Multi whether it pins several tags. Bump is the part of the version
+	// an outdated result is behind by: BumpMajor, BumpMinor, BumpPatch or
+	// BumpUnknown.
+	Index int  `json:"-"`
+	Multi bool `json:"-"`
+	Bump  int  `json:"-"`
+}
+
+// Checker checks sources against their upstreams, configured by its fields.
+// NewChecker returns one with the defaults of the sourcerer command. Fields
+// must not be changed once a check started; a Checker is otherwise safe for
+// concurrent use, and must not be copied after first use.
+type Checker struct {
+	// Client makes every request.
+	Client *http.Client
+	// Token authenticates GitHub API requests. When empty $GITHUB_TOKEN is
+	// used, or else the password of the API host in netrc.
+	Token string
+	// APIBase is the GitHub API base URL of sources that set none. When
+	// empty $GITHUB_API_URL is used, or else https://api.github.com.
+	APIBase string
+	// Retries is how many times a failed request is retried, RetryDelay the
+	// delay before the first retry, doubled after each.
+	Retries    int
+	RetryDelay time.Duration
+	// Rate is the maximum number of requests per second; 0 allows 10 with a
+	// GitHub token and 2 without.
+	Rate float64
+	// Concurrency bounds the number of sources looked up at once.
+	Concurrency int
+	// MaxPages is the maximum number of pages of tags or releases fetched
+	// per source.
+	MaxPages int
+	// CacheDir is the directory GitHub API responses are cached in; empty
+	// disables caching. Cached responses younger than CacheTTL are used
+	// without revalidating them.
+	CacheDir string
+	CacheTTL time.Duration
+	// Timeout bounds each exec provider command; 0 means no timeout.
+	Timeout time.Duration
+	// MinBump is the smallest bump reported as outdated: BumpMajor,
+	// BumpMinor or BumpPatch.
+	MinBump int
+	// NoVerify skips downloading release assets to verify their SHA256.
+	NoVerify bool
+	// EOLAPI is the base URL of the endoflife.date API.
+	EOLAPI string
+	// Strict rejects manifests pinning a tag that is not a version, and
+	// NoEnv leaves environment variables in manifests unexpanded.
+	Strict bool
+	NoEnv  bool
+	// Snapshot, if not nil, is checked against instead of upstream.
+	Snapshot *Snapshot
+	// Filter, if not nil, selects the sources CheckNewer checks.
+	Filter func(SourceEntry) bool
+	// Logf, if not nil, receives the warnings and debugging output of
+	// checks.
+	Logf func(level Level, format string, args ...interface{})
+	// Explain, if not nil, receives an explanation of how the versions of
+	// each source were parsed and compared.
+	Explain func(explanation string)
+	// Checked, if not nil, is called after each source CheckNewer checks.
+	Checked func()
+
+	once    sync.Once
+	limiter *limiter
+	sem     chan struct{}
+	memo    latestMemo
+}
+
+// NewChecker returns a Checker with the defaults of the sourcerer command.
+func NewChecker() *Checker {
+	return &Checker{
+		Client:      &http.Client{Timeout: 30 * time.Second},
+		Retries:     3,
+		RetryDelay:  500 * time.Millisecond,
+		Concurrency: runtime.NumCPU(),
+		MaxPages:    10,
+		Timeout:     30 * time.Second,
+		MinBump:     BumpPatch,
+		EOLAPI:      "https://endoflife.date/api",
+	}
+}
+
+// init sets up the state shared by the checks of c from its fields.
+func (c *Checker) init() {
+	c.once.Do(func() {
+		rate := c.Rate
+		if rate <= 0 {
+			rate = c.defaultRate()
+		}
+		c.limiter = newLimiter(rate)
+		n := c.Concurrency
+		if n < 1 {
+			n = 1
+		}
+		c.sem = make(chan struct{}, n)
+	})
+}
+
+func (c *Checker) client() *http.Client {
+	if c.Client == nil {
+		return http.DefaultClient
+	}
+	return c.Client
+}
+
+// Level is the severity of a message passed to Checker.Logf.
+type Level int
+
+const (
+	LevelError Level = iota
+	LevelWarn
+	LevelInfo
+	LevelDebug
+)
+
+func (c *Checker) logf(level Level, format string, args ...interface{}) {
+	if c.Logf != nil {
+		c.Logf(level, format, args...)
+	}
+}
+
+func (c *Checker) debugf(format string, args ...interface{}) { c.logf(LevelDebug, format, args...) }
+func (c *Checker) infof(format string, args ...interface{})  { c.logf(LevelInfo, format, args...) }
+func (c *Checker) warnf(format string, args ...interface{})  { c.logf(LevelWarn, format, args...) }
@@ -0,0 +1,215 @@
+package sourcerer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Version extracts the version from tag according to e's tag naming scheme:
+// the version group of TagPattern if set, otherwise tag without TagPrefix or
+// the TagComponent path. ok is false when tag does not follow the scheme.
+func (e SourceEntry) Version(tag string) (v string, ok bool) {
+	if e.TagPattern != "" {
+		re, err := regexp.Compile(e.TagPattern)
+		if err != nil {
+			return "", false
+		}
+		m := re.FindStringSubmatch(tag)
+		if len(m) < 2 {
+			return "", false
+		}
+		if i := re.SubexpIndex("version"); i > 0 {
+			return m[i], m[i] != ""
+		}
+		return m[1], true
+	}
+	prefix := e.TagPrefix
+	if e.TagComponent != "" {
+		prefix = strings.TrimSuffix(e.TagComponent, "/") + "/"
+	}
+	if prefix != "" {
+		if !strings.HasPrefix(tag, prefix) {
+			return "", false
+		}
+		return strings.TrimPrefix(tag, prefix), true
+	}
+	return tag, true
+}
+
+// candidate reports whether the upstream tag may be selected as e's latest
+// version: it follows e's naming scheme, is on the tracked release line and,
+// with StableOnly, is not a prerelease.
+func (e SourceEntry) candidate(tag string) bool {
+	v, ok := e.Version(tag)
+	if !ok || !e.tracks(v) {
+		return false
+	}
+	return !e.StableOnly || !isPrerelease(v)
+}
+
+// pins returns the entries e is checked as: e itself, or one per tag of Tags.
+// Each of several tags is compared within its own major version, so that an
+// LTS pin is compared with the latest of its line, except for the highest,
+// which is compared with the latest overall. An explicit Track applies to all
+// of them instead.
+func (e SourceEntry) pins() []SourceEntry {
+	if len(e.Tags) == 0 {
+		return []SourceEntry{e}
+	}
+	pins := make([]SourceEntry, len(e.Tags))
+	highest := -1
+	for i, t := range e.Tags {
+		pins[i] = e
+		pins[i].Tag, pins[i].Tags = t, nil
+		if highest < 0 {
+			highest = i
+		} else if rel, err := e.compareVersions(pins[highest].versionOf(), pins[i].versionOf()); err == nil && rel < 0 {
+			highest = i
+		}
+	}
+	for i := range pins {
+		if e.Track != "" || i == highest || IsConstraint(pins[i].Tag) || e.Versioning == versioningCalver || e.Versioning == versioningLexical {
+			continue
+		}
+		p := pins[i]
+		p.Track = "major"
+		if parts, ok := p.trackedParts(); ok {
+			pins[i].Track = fmt.Sprintf("%d.x", parts[0])
+		}
+	}
+	return pins
+}
+
+// versionOf returns the version in e's tag, or the tag itself.
+func (e SourceEntry) versionOf() string {
+	if v, ok := e.Version(e.Tag); ok {
+		return v
+	}
+	return e.Tag
+}
+
+// AllPins returns the pins of every source of config, in order.
+func AllPins(config Config) []SourceEntry {
+	pins := []SourceEntry{}
+	for _, e := range config.Sources {
+		pins = append(pins, e.pins()...)
+	}
+	return pins
+}
+
+var trackRE = regexp.MustCompile(`^(major|minor|\d+(\.\d+)*\.x)$`)
+
+// trackedParts returns the leading version parts a version must have to be on
+// the release line e tracks, and false when it tracks none.
+func (e SourceEntry) trackedParts() ([]int, bool) {
+	switch e.Track {
+	case "":
+		return nil, false
+	case "major", "minor":
+		pin := e.Tag
+		if v, ok := e.Version(e.Tag); ok {
+			pin = v
+		}
+		sv, err := mkSemver(pin)
+		if err != nil {
+			return nil, false
+		}
+		n := 1
+		if e.Track == "minor" {
+			n = 2
+		}
+		for len(sv.parts) < n {
+			sv.parts = append(sv.parts, 0)
+		}
+		return sv.parts[:n], true
+	}
+	sv, err := mkSemver(strings.TrimSuffix(e.Track, ".x"))
+	if err != nil {
+		return nil, false
+	}
+	return sv.parts, true
+}
+
+// tracks reports whether version v is on the release line e tracks.
+func (e SourceEntry) tracks(v string) bool {
+	want, ok := e.trackedParts()
+	if !ok {
+		return true
+	}
+	sv, err := mkSemver(v)
+	if err != nil {
+		return false
+	}
+	for i, p := range want {
+		if i >= len(sv.parts) {
+			if p != 0 {
+				return false
+			}
+		} else if sv.parts[i] != p {
+			return false
+		}
+	}
+	return true
+}
+
+// validateTagScheme checks that e uses at most one tag naming scheme, that its
+// TagPattern, if any, compiles and captures the version, and that its Track,
+// if any, is valid for it.
+func validateTagScheme(e SourceEntry) error {
+	schemes := 0
+	for _, s := range []string{e.TagPrefix, e.TagPattern, e.TagComponent} {
+		if s != "" {
+			schemes++
+		}
+	}
+	if schemes > 1 {
+		return fmt.Errorf("cannot define more than one of tag_prefix, tag_pattern and tag_component; pick one")
+	}
+	if e.Track != "" {
+		if !trackRE.MatchString(e.Track) {
+			return fmt.Errorf("invalid track %q; expected major, minor or a release line such as 1.x", e.Track)
+		}
+		if IsConstraint(e.Tag) {
+			return fmt.Errorf("a track cannot be used with a version constraint")
+		}
+		if isRegistryProvider(e.Provider) || e.Provider == ProviderExec || e.URL != "" {
+			return fmt.Errorf("a track can only be used with github, gitlab and bitbucket repos")
+		}
+	}
+	if e.TagPattern == "" {
+		return nil
+	}
+	re, err := regexp.Compile(e.TagPattern)
+	if err != nil {
+		return fmt.Errorf("invalid tag_pattern\n%v", err)
+	}
+	if re.NumSubexp() < 1 {
+		return fmt.Errorf("tag_pattern %q must capture the version in a group", e.TagPattern)
+	}
+	return nil
+}
+
+// highestVersion returns the tag with the highest version among the
+// candidates in tags, ignoring tags that are not versions. ok is false when no
+// tag could be compared.
+func highestVersion(e SourceEntry, tags []string) (best string, ok bool) {
+	var bestV string
+	for _, t := range tags {
+		if !e.candidate(t) {
+			continue
+		}
+		v, _ := e.Version(t)
+		if err := e.parseVersion(v); err != nil {
+			continue
+		}
+		if !ok {
+			best, bestV, ok = t, v, true
+			continue
+		}
+		if rel, err := e.compareVersions(bestV, v); err == nil && rel < 0 {
+			best, bestV = t, v
+		}
+	}
+	return best, ok
+}
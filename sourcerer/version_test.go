@@ -0,0 +1,81 @@
+package sourcerer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVersionTagSchemes(t *testing.T) {
+	for _, c := range []struct {
+		e     SourceEntry
+		tag   string
+		parts []int
+	}{
+		{SourceEntry{}, "v1.2.3", []int{1, 2, 3}},
+		{SourceEntry{TagPrefix: "release-"}, "release-1.2.3", []int{1, 2, 3}},
+		{SourceEntry{TagPattern: `^myproj-v(\d+\.\d+\.\d+)-final$`}, "myproj-v1.2.3-final", []int{1, 2, 3}},
+	} {
+		v, ok := c.e.Version(c.tag)
+		if !ok {
+			t.Errorf("%s does not follow the tag scheme", c.tag)
+			continue
+		}
+		sv, err := mkSemver(v)
+		if err != nil || !reflect.DeepEqual(sv.parts, c.parts) || len(sv.prerelease) > 0 {
+			t.Errorf("%s: version %s parses as %v %v, %v; want %v", c.tag, v, sv.parts, sv.prerelease, err, c.parts)
+		}
+	}
+}
+
+func TestVersionOutsideTagScheme(t *testing.T) {
+	for _, c := range []struct {
+		e   SourceEntry
+		tag string
+	}{
+		{SourceEntry{TagPrefix: "release-"}, "v1.2.3"},
+		{SourceEntry{TagPattern: `^myproj-v(\d+\.\d+\.\d+)-final$`}, "myproj-v1.2.3"},
+	} {
+		if v, ok := c.e.Version(c.tag); ok {
+			t.Errorf("%s: got version %s, want the tag to be ignored", c.tag, v)
+		}
+	}
+}
+
+func TestVersionMonorepoTags(t *testing.T) {
+	tags := []string{"api/v1.2.3", "worker/v2.0.0", "api/v1.1.0", "v3.0.0"}
+	for _, c := range []struct {
+		e       SourceEntry
+		tag     string
+		version string
+		highest string
+	}{
+		{SourceEntry{TagComponent: "api"}, "api/v1.2.3", "v1.2.3", "api/v1.2.3"},
+		{SourceEntry{TagComponent: "worker/"}, "worker/v2.0.0", "v2.0.0", "worker/v2.0.0"},
+		{SourceEntry{TagPattern: `^(api|worker)/v(?P<version>\d+\.\d+\.\d+)$`}, "api/v1.2.3", "1.2.3", "worker/v2.0.0"},
+	} {
+		if v, ok := c.e.Version(c.tag); !ok || v != c.version {
+			t.Errorf("%+v: got version %q, %v of %s; want %q", c.e, v, ok, c.tag, c.version)
+		}
+		if best, ok := highestVersion(c.e, tags); !ok || best != c.highest {
+			t.Errorf("%+v: got highest %q, %v; want %q", c.e, best, ok, c.highest)
+		}
+	}
+}
+
+func TestHighestVersionOnTrack(t *testing.T) {
+	tags := []string{"v2.0.0", "v1.5.0", "v1.4.2", "v1.5.1-rc.1"}
+	for _, c := range []struct {
+		e       SourceEntry
+		highest string
+	}{
+		{SourceEntry{}, "v2.0.0"},
+		{SourceEntry{Track: "1.x", StableOnly: true}, "v1.5.0"},
+		{SourceEntry{Track: "1.4.x"}, "v1.4.2"},
+		{SourceEntry{Tag: "v1.4.0", Track: "major", StableOnly: true}, "v1.5.0"},
+		{SourceEntry{Tag: "v1.4.0", Track: "minor"}, "v1.4.2"},
+	} {
+		if best, ok := highestVersion(c.e, tags); !ok || best != c.highest {
+			t.Errorf("track %q: got highest %q, %v; want %q", c.e.Track, best, ok, c.highest)
+		}
+	}
+}
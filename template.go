@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/estk/sourcerer/sourcerer"
+)
+
+var templateFlag = flag.String("template", "", "print each result through this text/template instead of the text output, e.g. '{{label .}} {{.CurrentTag}} -> {{.LatestTag}} ({{.Status}})', or 'default' for the lines of the text output")
+
+// defaultTemplate is the template selected by -template=default, rendering
+// each result as the text output does, without the heading naming its
+// manifest.
+const defaultTemplate = "{{message .}}"
+
+// resultTemplate is -template as parsed by setup, or nil. Results expose the
+// fields of the JSON output, such as .Manifest, .Repo, .CurrentTag,
+// .LatestTag and .Status; label returns the repo, name or url of a result and
+// message its line of the text output.
+var resultTemplate *template.Template
+
+func parseResultTemplate(text string) (*template.Template, error) {
+	if text == "default" {
+		text = defaultTemplate
+	}
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	t, err := template.New("result").Funcs(template.FuncMap{"label": resultLabel, "message": message}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -template\n%v", err)
+	}
+	return t, nil
+}
+
+// printTemplate prints the shown results through resultTemplate in a single
+// write. A result failing to render is logged and skipped.
+func printTemplate(results []sourcerer.Result) {
+	var buf bytes.Buffer
+	for _, r := range results {
+		if !shown(r) {
+			continue
+		}
+		var out bytes.Buffer
+		if err := resultTemplate.Execute(&out, r); err != nil {
+			errorf("cannot render %s with -template: %v", resultLabel(r), err)
+			continue
+		}
+		buf.Write(out.Bytes())
+	}
+	os.Stdout.Write(buf.Bytes())
+}
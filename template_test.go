@@ -0,0 +1,29 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/estk/sourcerer/sourcerer"
+)
+
+func TestDefaultTemplateMatchesMessage(t *testing.T) {
+	tmpl, err := parseResultTemplate("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []sourcerer.Result{
+		{Repo: "github.com/org/a", CurrentTag: "v1.0.0", LatestTag: "v2.0.0", Status: sourcerer.StatusOutdated},
+		{Repo: "github.com/org/b", CurrentTag: "v2.0.0", LatestTag: "v2.0.0", Status: sourcerer.StatusUpToDate},
+		{Name: "lib", CurrentTag: ">=1.4.0", LatestTag: "1.3.0", Status: sourcerer.StatusOutdated},
+		{Repo: "github.com/org/c", Detail: "rate limited", Status: sourcerer.StatusUnknown},
+	} {
+		var out bytes.Buffer
+		if err := tmpl.Execute(&out, r); err != nil {
+			t.Fatal(err)
+		}
+		if want := message(r) + "\n"; out.String() != want {
+			t.Errorf("%s: got %q, want %q", resultLabel(r), out.String(), want)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+package main
+
+import "io"
+
+// terminalWidth cannot query the console on Windows, so tables are fit to
+// $COLUMNS only.
+func terminalWidth(w io.Writer) (int, bool) {
+	return 0, false
+}
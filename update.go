@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/estk/sourcerer/sourcerer"
+)
+
+var (
+	yamlSourcesRE = regexp.MustCompile(`^sources:\s*(#.*)?$`)
+	yamlItemRE    = regexp.MustCompile(`^( *)- `)
+	yamlTagRE     = regexp.MustCompile(`^( *(?:- )?)tag:(\s*)("[^"]*"|'[^']*'|[^\s#]+)(.*)$`)
+	tomlTableRE   = regexp.MustCompile(`^\s*\[\[\s*sources\s*\]\]`)
+	tomlHeaderRE  = regexp.MustCompile(`^\s*\[`)
+	tomlTagRE     = regexp.MustCompile(`^(\s*tag\s*=\s*)("[^"]*"|'[^']*')(.*)$`)
+)
+
+// tagChange is a rewrite of one line of a manifest.
+type tagChange struct {
+	line     int
+	old, new string
+}
+
+// runUpdate rewrites the tag of every outdated source to the latest version
+// found upstream. Only the tag lines change so comments and ordering are
+// kept. With -dry-run the changes are printed as a diff instead of written.
+func runUpdate(ctx context.Context, manifests []string) int {
+	var counts tally
+	results, errs := checkManifests(ctx, manifests, &counts, nil)
+	if ctx.Err() != nil {
+		errorf("interrupted")
+		return exitError
+	}
+	status := exitOK
+	for i, m := range manifests {
+		if errs[i] != nil {
+			errorf("%s: %v", m, errs[i])
+			status = exitError
+		}
+		tags := map[int]string{}
+		for _, r := range results[i] {
+			if r.Status != sourcerer.StatusOutdated || r.URL != "" || r.Multi || sourcerer.IsConstraint(r.CurrentTag) {
+				continue
+			}
+			tags[r.Index] = r.LatestTag
+		}
+		if len(tags) == 0 {
+			continue
+		}
+		if err := updateManifest(m, tags); err != nil {
+			errorf("%s: %v", m, err)
+			status = exitError
+		}
+	}
+	return status
+}
+
+// updateManifest sets the tag of the sources of manifest m given by index in
+// tags.
+func updateManifest(m string, tags map[int]string) error {
+	if m == stdinManifest {
+		return fmt.Errorf("cannot update a manifest read from stdin")
+	}
+	if m == adhocManifest {
+		return fmt.Errorf("cannot update a source given with -repo")
+	}
+	data, err := ioutil.ReadFile(m)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(string(data), "\n")
+	var changes []tagChange
+	switch strings.ToLower(filepath.Ext(m)) {
+	case ".json":
+		return fmt.Errorf("updating JSON manifests is not supported")
+	case ".toml":
+		changes = tomlTagChanges(lines, tags)
+	default:
+		changes = yamlTagChanges(lines, tags)
+	}
+	if len(changes) < len(tags) {
+		warnf("%s: could not find the tag of %d sources to update", m, len(tags)-len(changes))
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	if *dryRunFlag {
+		fmt.Printf("--- %s\n+++ %s\n", m, m)
+		for _, c := range changes {
+			fmt.Printf("@@ -%d +%d @@\n-%s\n+%s\n", c.line+1, c.line+1, c.old, c.new)
+		}
+		return nil
+	}
+	for _, c := range changes {
+		lines[c.line] = c.new
+	}
+	if err := writeFileAtomic(m, []byte(strings.Join(lines, "\n"))); err != nil {
+		return err
+	}
+	fmt.Printf("Updated %d sources in %s\n", len(changes), m)
+	return nil
+}
+
+// yamlTagChanges finds the tag lines of the block style sources sequence in a
+// YAML manifest and rewrites those of the sources in tags, keeping any quoting
+// and trailing comment. Tags built from environment variables are left alone,
+// as are anchored and aliased ones, which other sources may share.
+func yamlTagChanges(lines []string, tags map[int]string) []tagChange {
+	var changes []tagChange
+	inSources := false
+	itemIndent := -1
+	index := -1
+	for i, l := range lines {
+		if yamlSourcesRE.MatchString(l) {
+			inSources = true
+			continue
+		}
+		if !inSources || strings.TrimSpace(l) == "" || strings.HasPrefix(strings.TrimSpace(l), "#") {
+			continue
+		}
+		if l[0] != ' ' && l[0] != '-' {
+			// Another top level key ends the sources.
+			inSources = false
+			continue
+		}
+		if m := yamlItemRE.FindStringSubmatch(l); m != nil && (itemIndent < 0 || len(m[1]) == itemIndent) {
+			itemIndent = len(m[1])
+			index++
+		}
+		m := yamlTagRE.FindStringSubmatch(l)
+		if m == nil || len(m[1]) != itemIndent+2 {
+			continue
+		}
+		if tag, ok := tags[index]; ok && !strings.Contains(m[3], "$") && !strings.ContainsAny(m[3][:1], "&*") {
+			changes = append(changes, tagChange{line: i, old: l, new: m[1] + "tag:" + m[2] + requote(m[3], tag) + m[4]})
+		}
+	}
+	return changes
+}
+
+// tomlTagChanges finds the tag keys of the [[sources]] tables in a TOML
+// manifest and rewrites those of the sources in tags.
+func tomlTagChanges(lines []string, tags map[int]string) []tagChange {
+	var changes []tagChange
+	inSources := false
+	index := -1
+	for i, l := range lines {
+		if tomlTableRE.MatchString(l) {
+			inSources = true
+			index++
+			continue
+		}
+		if tomlHeaderRE.MatchString(l) {
+			inSources = false
+			continue
+		}
+		m := tomlTagRE.FindStringSubmatch(l)
+		if !inSources || m == nil {
+			continue
+		}
+		if tag, ok := tags[index]; ok && !strings.Contains(m[2], "$") {
+			changes = append(changes, tagChange{line: i, old: l, new: m[1] + requote(m[2], tag) + m[3]})
+		}
+	}
+	return changes
+}
+
+// requote returns tag quoted the same way as old.
+func requote(old, tag string) string {
+	if len(old) >= 2 && (old[0] == '"' || old[0] == '\'') {
+		return string(old[0]) + tag + string(old[0])
+	}
+	return tag
+}
+
+// writeFileAtomic replaces the file at path with data, keeping its mode. The
+// data is written to a temporary file that is renamed into place, so the file
+// is never left half written.
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0644)
+	if fi, err := os.Stat(path); err == nil {
+		mode = fi.Mode()
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), mode)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
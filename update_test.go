@@ -0,0 +1,25 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestYAMLTagChangesSkipsAnchors(t *testing.T) {
+	manifest := `sources:
+  - repo: github.com/org/a
+    tag: &tag v1.0.0
+  - repo: github.com/org/b
+    tag: *tag
+  - repo: github.com/org/c
+    tag: "v1.0.0" # keep
+`
+	lines := strings.Split(manifest, "\n")
+	changes := yamlTagChanges(lines, map[int]string{0: "v2.0.0", 1: "v2.0.0", 2: "v2.0.0"})
+	if len(changes) != 1 {
+		t.Fatalf("got %d changes, want only the unanchored tag: %v", len(changes), changes)
+	}
+	if c := changes[0]; c.line != 6 || c.new != `    tag: "v2.0.0" # keep` {
+		t.Errorf("got line %d rewritten to %q", c.line, c.new)
+	}
+}
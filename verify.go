@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// runVerify checks the manifests given as arguments, as a pre-commit hook
+// would pass them, and prints one line for every source violating the
+// -fail-on policy: outdated by at least -min-bump, or pinned to a version
+// missing upstream. It exits exitFailed when there are any.
+func runVerify(ctx context.Context, manifests []string) int {
+	if len(manifests) == 0 {
+		return exitOK
+	}
+	var counts tally
+	results, errs := checkManifests(ctx, manifests, &counts, nil)
+	if ctx.Err() != nil {
+		errorf("interrupted")
+		return exitError
+	}
+	status := exitOK
+	for i, rs := range results {
+		if errs[i] != nil {
+			errorf("%s: %v", manifests[i], errs[i])
+			status = exitError
+		}
+		for _, r := range rs {
+			if !shouldFail(r.Status) {
+				continue
+			}
+			line := fmt.Sprintf("%s: %s: %s %s", manifests[i], resultLabel(r), r.Status, r.CurrentTag)
+			if r.LatestTag != "" {
+				line += " -> " + r.LatestTag
+			}
+			fmt.Println(line)
+			if status == exitOK {
+				status = exitFailed
+			}
+		}
+	}
+	return status
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/estk/sourcerer/sourcerer"
+)
+
+var (
+	watchFlag    = flag.Duration("watch", 0, "re-run the check on this interval until interrupted; 0 checks once")
+	watchAllFlag = flag.Bool("watch-all", false, "with -watch, print every result each cycle instead of only those that changed")
+)
+
+// runWatch checks manifests every -watch interval until ctx is done, on
+// SIGINT or SIGTERM, rediscovering manifests before each cycle after the
+// first. Only results that changed since the previous cycle are printed
+// unless -watch-all is set. Stopping is how a watch ends, so it exits with
+// exitOK rather than as an interrupted check.
+func runWatch(ctx context.Context, manifests []string) int {
+	ticker := time.NewTicker(*watchFlag)
+	defer ticker.Stop()
+
+	var m *metrics
+	if *metricsAddrFlag != "" {
+		m = &metrics{}
+		if err := serveMetrics(*metricsAddrFlag, m); err != nil {
+			errorf("%v", err)
+			return exitError
+		}
+	}
+
+	var last map[string]sourcerer.Result
+	for {
+		last = watchCycle(ctx, manifests, last, m)
+		if ctx.Err() != nil {
+			infof("stopping")
+			return exitOK
+		}
+		select {
+		case <-ctx.Done():
+			infof("stopping")
+			return exitOK
+		case <-ticker.C:
+		}
+		manifests, _ = discover()
+	}
+}
+
+// watchCycle runs one check of manifests, printing and notifying the results
+// that differ from last and updating m if it is not nil, and returns the
+// results of this cycle keyed by watchKey.
+func watchCycle(ctx context.Context, manifests []string, last map[string]sourcerer.Result, m *metrics) map[string]sourcerer.Result {
+	now := time.Now()
+	var counts tally
+	results, errs := checkManifests(ctx, manifests, &counts, nil)
+	if ctx.Err() != nil {
+		// Interrupted; the partial results are of no use.
+		return last
+	}
+	if m != nil {
+		m.update(results, errs)
+	}
+
+	current := map[string]sourcerer.Result{}
+	changed := []sourcerer.Result{}
+	// byManifest holds the changed results of each manifest, for printing.
+	byManifest := [][]sourcerer.Result{}
+	for _, rs := range results {
+		var ms []sourcerer.Result
+		for _, r := range rs {
+			key := watchKey(r)
+			current[key] = r
+			prev, seen := last[key]
+			if shown(r) && (*watchAllFlag || !seen || prev.Status != r.Status || prev.CurrentTag != r.CurrentTag || prev.LatestTag != r.LatestTag) {
+				ms = append(ms, r)
+			}
+		}
+		changed = append(changed, ms...)
+		if len(ms) > 0 {
+			byManifest = append(byManifest, ms)
+		}
+	}
+
+	if *formatFlag == "json" {
+		if err := json.NewEncoder(os.Stdout).Encode(struct {
+			Time    time.Time          `json:"time"`
+			Results []sourcerer.Result `json:"results"`
+		}{now, changed}); err != nil {
+			errorf("%v", err)
+		}
+	} else if *formatFlag == "lines" {
+		if err := writeLines(os.Stdout, changed); err != nil {
+			errorf("%v", err)
+		}
+	} else if *formatFlag == "table" {
+		if err := writeTable(os.Stdout, changed); err != nil {
+			errorf("%v", err)
+		}
+	} else {
+		if resultTemplate == nil {
+			fmt.Printf("[%s] Checked %d manifests\n", now.Format("2006-01-02 15:04:05"), len(manifests))
+		}
+		for _, ms := range byManifest {
+			printManifest(ms)
+		}
+		fmt.Fprintln(os.Stderr, counts.summary(len(manifests)))
+	}
+	for i, err := range errs {
+		if err != nil {
+			errorf("%s: %v", manifests[i], err)
+		}
+	}
+	if err := notify(ctx, [][]sourcerer.Result{changed}); err != nil {
+		errorf("%v", err)
+	}
+	return current
+}
+
+// watchKey identifies the source r reports on across watch cycles, and for
+// sources pinning several tags the pin.
+func watchKey(r sourcerer.Result) string {
+	pin := ""
+	if r.Multi {
+		pin = r.CurrentTag
+	}
+	return fmt.Sprintf("%s\x00%d\x00%s\x00%s\x00%s\x00%s", r.Manifest, r.Index, r.Repo, r.Name, r.URL, pin)
+}
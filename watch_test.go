@@ -0,0 +1,23 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/estk/sourcerer/sourcerer"
+)
+
+func TestWatchKeySeparatesPins(t *testing.T) {
+	r := sourcerer.Result{Manifest: "SOURCES", Repo: "github.com/org/server", CurrentTag: "v1.8.0", Track: "1.x", Multi: true}
+	other := r
+	other.CurrentTag, other.Track = "v2.3.0", ""
+	if watchKey(r) == watchKey(other) {
+		t.Errorf("pins %s and %s of one source share the key %q", r.CurrentTag, other.CurrentTag, watchKey(r))
+	}
+
+	single := sourcerer.Result{Manifest: "SOURCES", Repo: "github.com/org/lib", CurrentTag: "v1.0.0"}
+	bumped := single
+	bumped.CurrentTag = "v1.1.0"
+	if watchKey(single) != watchKey(bumped) {
+		t.Errorf("bumping the tag of a single tag source changed its key")
+	}
+}